@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"sort"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// In conflict zones kills are rewarded with combat bonds (FactionKillBond) instead of bounties.
+// In conflict-zone mode bonds are counted apart from the bounties, for each side the commander
+// is fighting for.
+
+// sideStats is the kills and combat bonds of a side of a conflict, as seen by the templates
+type sideStats struct {
+	Faction string // awarding faction
+	Kills   int
+	Reward  int64 // credits
+}
+
+func (e *Notifier) addBond(j *journal.FactionKillBond) {
+	faction := j.AwardingFactionLocalised
+	if faction == "" {
+		faction = j.AwardingFaction
+	}
+
+	side := e.bondSides[faction]
+	side.Faction = faction
+	side.Kills++
+	side.Reward += j.Reward
+	e.bondSides[faction] = side
+
+	e.bondKills++
+	e.totalBonds += j.Reward
+}
+
+// sides returns the stats of each side, the most rewarding first
+func (e *Notifier) sides() []sideStats {
+	sides := make([]sideStats, 0, len(e.bondSides))
+	for _, side := range e.bondSides {
+		sides = append(sides, side)
+	}
+	sort.Slice(sides, func(i, j int) bool {
+		if sides[i].Reward != sides[j].Reward {
+			return sides[i].Reward > sides[j].Reward
+		}
+		return sides[i].Faction < sides[j].Faction
+	})
+
+	return sides
+}
+
+// factionKillBondEvent notifies the combat bonds like bountyEvent the bounties
+func factionKillBondEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if !e.cfg.ConflictZones {
+		return nil
+	}
+
+	j := ev.(*journal.FactionKillBond)
+	e.addBond(j)
+
+	printLog(j, "Enemies killed:", e.bondKills)
+
+	bonds, _ := formatCredits(message.NewPrinter(language.English), e.totalBonds)
+	printLog(j, "Total combat bonds:", bonds)
+
+	if !e.cfg.KillsNotifs {
+		return nil
+	}
+
+	if !e.cfg.KillsSilentNotifs || e.bondKills%10 == 0 {
+		return e.notifyTemplate(combatBondsTemplate, categoryKills, j, skipNotify)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_factionKillBondEvent(t *testing.T) {
+	bond := &journal.FactionKillBond{
+		Reward:          80000,
+		AwardingFaction: "$faction_Federation;", AwardingFactionLocalised: "Federation",
+		VictimFaction: "$faction_Empire;", VictimFactionLocalised: "Empire",
+	}
+	other := &journal.FactionKillBond{Reward: 120000, AwardingFaction: "Ngalinn Purple Creative Industry", VictimFaction: "Ngalinn Crimson Boys"}
+
+	tests := []struct {
+		name     string
+		cfg      *Cfg
+		bonds    []*journal.FactionKillBond
+		wantMsgs int
+		wantMsg  string
+	}{
+		{
+			name:  "disabled",
+			cfg:   &Cfg{ConflictZones: true},
+			bonds: []*journal.FactionKillBond{bond, other},
+		},
+		{
+			name:  "not in conflict-zone mode",
+			cfg:   &Cfg{KillsNotifs: true},
+			bonds: []*journal.FactionKillBond{bond, other},
+		},
+		{
+			name:     "every kill",
+			cfg:      &Cfg{ConflictZones: true, KillsNotifs: true},
+			bonds:    []*journal.FactionKillBond{bond, other, bond},
+			wantMsgs: 3,
+			wantMsg:  "Combat bonds: 280,000 credits\nEnemies killed: 3\n- Federation: 160,000 credits, kills: 2\n- Ngalinn Purple Creative Industry: 120,000 credits, kills: 1",
+		},
+		{
+			name:     "silent",
+			cfg:      &Cfg{ConflictZones: true, KillsNotifs: true, KillsSilentNotifs: true},
+			bonds:    []*journal.FactionKillBond{bond, bond, bond, bond, bond, bond, bond, bond, bond, bond, bond},
+			wantMsgs: 1,
+			wantMsg:  "Combat bonds: 800,000 credits\nEnemies killed: 10\n- Federation: 800,000 credits, kills: 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Notifier{cfg: tt.cfg}
+			n.initCounters()
+
+			var msgs int
+			var msg string
+			for _, b := range tt.bonds {
+				bot := &mockBot{}
+				n.bot = bot
+				if err := factionKillBondEvent(n, b, false); err != nil {
+					t.Fatal(err)
+				}
+				if bot.sentMsg != "" {
+					msgs++
+					msg = bot.sentMsg
+				}
+			}
+
+			if msgs != tt.wantMsgs {
+				t.Fatalf("wantMsgs: %d, got: %d", tt.wantMsgs, msgs)
+			}
+			if msg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, msg)
+			}
+
+			// Combat bonds don't change the bounty counters
+			if got := fmt.Sprint(n.killedPirates, n.totalPiratesReward); got != "0 0" {
+				t.Fatalf("want no bounties, got: %s", got)
+			}
+		})
+	}
+}
+
+func TestNotifier_quietSummaryBonds(t *testing.T) {
+	n := &Notifier{cfg: &Cfg{ConflictZones: true}}
+	n.initCounters()
+	n.addBond(&journal.FactionKillBond{Reward: 80000, AwardingFaction: "Federation"})
+	n.addBond(&journal.FactionKillBond{Reward: 120000, AwardingFaction: "Federation"})
+
+	msg, err := n.execute(quietSummaryTemplate, map[string]interface{}{
+		"Suppressed": []suppressedItem{{Time: "03:12", Text: "Shields are down!"}},
+		"Session":    n.sessionStats(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Combat bonds: 200,000 credits, enemies killed: 2"; !strings.HasSuffix(msg, want) {
+		t.Fatalf("want: a summary ending with %q, got: %q", want, msg)
+	}
+}
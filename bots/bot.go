@@ -1,6 +1,78 @@
 package bots
 
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Timeout of the HTTP requests to the backends. Telegram uses long polling with a 60
+// seconds timeout to receive the commands, so this must be longer than that.
+const httpTimeout = 90 * time.Second
+
 type Bot interface {
 	Start()
 	Send(string) error
 }
+
+// Checker is implemented by bots that can verify their connection to the backend
+// without sending a notification
+type Checker interface {
+	Check() error
+}
+
+// Message is a notification with its delivery options
+type Message struct {
+	Text   string
+	Silent bool // deliver without sound or popup, when the backend supports it
+}
+
+// MessageSender is implemented by bots supporting the delivery options of Message
+type MessageSender interface {
+	SendMessage(Message) error
+}
+
+// SendMessage sends the message with the bot, ignoring the delivery options the bot
+// doesn't support
+func SendMessage(b Bot, m Message) error {
+	if s, ok := b.(MessageSender); ok {
+		return s.SendMessage(m)
+	}
+
+	return b.Send(m.Text)
+}
+
+// RateLimitError is returned when the backend rejects a message because too many messages
+// have been sent, asking to retry after some time
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// PermanentError is returned when the backend rejects a message and sending it again can't
+// succeed, e.g. because the token is invalid or the request is malformed
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// isPermanentStatus returns whether an HTTP status code rejects the request for good, rather
+// than asking to retry it later
+func isPermanentStatus(code int) bool {
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
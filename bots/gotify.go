@@ -5,10 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// Highest Gotify priority that doesn't make clients play a sound
+const silentPriority = 3
+
 type Gotify struct {
 	url      string
 	token    string
@@ -47,7 +52,7 @@ func NewGotify(url, token, title string, priority int) (*Gotify, error) {
 		token:    token,
 		title:    title,
 		priority: priority,
-		client:   &http.Client{},
+		client:   &http.Client{Timeout: httpTimeout},
 	}, nil
 }
 
@@ -62,6 +67,17 @@ func (g *Gotify) Send(text string) error {
 	return g.sendWithPriority(text, g.priority)
 }
 
+// SendMessage sends a message to Gotify server, lowering the priority of silent messages
+// so that clients don't play sounds or show popups
+func (g *Gotify) SendMessage(m Message) error {
+	priority := g.priority
+	if m.Silent && priority > silentPriority {
+		priority = silentPriority
+	}
+
+	return g.sendWithPriority(m.Text, priority)
+}
+
 // SendWithPriority sends a message to Gotify server with a specific priority
 func (g *Gotify) sendWithPriority(text string, priority int) error {
 	message := gotifyMessage{
@@ -89,9 +105,65 @@ func (g *Gotify) sendWithPriority(text string, priority int) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			return &RateLimitError{RetryAfter: time.Duration(seconds) * time.Second, Err: err}
+		}
+		return err
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if isPermanentStatus(resp.StatusCode) {
+			return &PermanentError{Err: err}
+		}
+		return err
 	}
 
 	return nil
 }
+
+type gotifyHealth struct {
+	Health   string `json:"health"`
+	Database string `json:"database"`
+}
+
+// Check verifies that the Gotify server is healthy and that it accepts the application token.
+// The token is verified posting an empty message, which Gotify rejects with 400 when the
+// token is valid and with 401/403 when it isn't, so nothing is delivered.
+func (g *Gotify) Check() error {
+	resp, err := g.client.Get(fmt.Sprintf("%s/health", g.url))
+	if err != nil {
+		return fmt.Errorf("cannot reach the server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned status code: %d", resp.StatusCode)
+	}
+
+	var h gotifyHealth
+	if err := json.NewDecoder(resp.Body).Decode(&h); err != nil {
+		return fmt.Errorf("cannot decode health response: %v", err)
+	}
+	if h.Health != "green" || h.Database != "green" {
+		return fmt.Errorf("server is unhealthy (health: %s, database: %s)", h.Health, h.Database)
+	}
+
+	url := fmt.Sprintf("%s/message?token=%s", g.url, g.token)
+	tokenResp, err := g.client.Post(url, "application/json", bytes.NewBufferString("{}"))
+	if err != nil {
+		return fmt.Errorf("cannot verify the token: %v", err)
+	}
+	defer tokenResp.Body.Close()
+
+	switch tokenResp.StatusCode {
+	case http.StatusBadRequest:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("the application token was rejected by the server")
+	default:
+		return fmt.Errorf("unexpected status code verifying the token: %d", tokenResp.StatusCode)
+	}
+}
@@ -0,0 +1,54 @@
+package bots
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Translations of the replies to the bot commands, keyed by the English text
+var replies = map[string]map[language.Tag]string{
+	"Available commands:": {
+		language.Italian: "Comandi disponibili:",
+		language.German:  "Verfügbare Befehle:",
+		language.French:  "Commandes disponibles :",
+		language.Spanish: "Comandos disponibles:",
+	},
+	"/help - Get this help": {
+		language.Italian: "/help - Mostra questo aiuto",
+		language.German:  "/help - Zeigt diese Hilfe an",
+		language.French:  "/help - Affiche cette aide",
+		language.Spanish: "/help - Muestra esta ayuda",
+	},
+	"/channel - Return the channel id": {
+		language.Italian: "/channel - Restituisce l'id del canale",
+		language.German:  "/channel - Gibt die Kanal-ID zurück",
+		language.French:  "/channel - Renvoie l'identifiant du canal",
+		language.Spanish: "/channel - Devuelve el id del canal",
+	},
+	"/check - Send a message using the channel id from the configuration file (to verify it's working)": {
+		language.Italian: "/check - Invia un messaggio usando l'id del canale del file di configurazione (per verificare che funzioni)",
+		language.German:  "/check - Sendet eine Nachricht an die Kanal-ID aus der Konfigurationsdatei (um zu prüfen, ob alles funktioniert)",
+		language.French:  "/check - Envoie un message à l'identifiant du canal du fichier de configuration (pour vérifier que tout fonctionne)",
+		language.Spanish: "/check - Envía un mensaje usando el id del canal del archivo de configuración (para comprobar que funciona)",
+	},
+	"Channel ID: %s": {
+		language.Italian: "ID del canale: %s",
+		language.German:  "Kanal-ID: %s",
+		language.French:  "Identifiant du canal : %s",
+		language.Spanish: "ID del canal: %s",
+	},
+	"If you received this message, everything is configured properly! :)": {
+		language.Italian: "Se hai ricevuto questo messaggio, è tutto configurato correttamente! :)",
+		language.German:  "Wenn du diese Nachricht erhalten hast, ist alles richtig konfiguriert! :)",
+		language.French:  "Si vous avez reçu ce message, tout est correctement configuré ! :)",
+		language.Spanish: "Si has recibido este mensaje, ¡todo está configurado correctamente! :)",
+	},
+}
+
+func init() {
+	for key, translations := range replies {
+		for lang, text := range translations {
+			message.SetString(lang, key, text)
+		}
+	}
+}
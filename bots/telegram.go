@@ -1,20 +1,27 @@
 package bots
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 type Telegram struct {
 	channelId int64
 	bot       *tgbotapi.BotAPI
+	printer   *message.Printer // translates the replies to the commands
 }
 
-func NewTelegram(token string, channelId int64) (*Telegram, error) {
-	bot, err := tgbotapi.NewBotAPI(token)
+func NewTelegram(token string, channelId int64, lang language.Tag) (*Telegram, error) {
+	bot, err := tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, &http.Client{Timeout: httpTimeout})
 	if err != nil {
 		return nil, err
 	}
@@ -22,6 +29,7 @@ func NewTelegram(token string, channelId int64) (*Telegram, error) {
 	return &Telegram{
 		bot:       bot,
 		channelId: channelId,
+		printer:   message.NewPrinter(lang),
 	}, nil
 }
 
@@ -43,9 +51,10 @@ func (bot *Telegram) Start() {
 				case "help":
 					msg.Text = bot.printHelp()
 				case "channel":
-					msg.Text = fmt.Sprintf("Channel ID: %d", update.Message.Chat.ID)
+					// Format the id as a string to avoid the thousands separators of the language
+					msg.Text = bot.printer.Sprintf("Channel ID: %s", strconv.FormatInt(update.Message.Chat.ID, 10))
 				case "check":
-					msg = tgbotapi.NewMessage(bot.channelId, "If you received this message, everything is configured properly! :)")
+					msg = tgbotapi.NewMessage(bot.channelId, bot.printer.Sprintf("If you received this message, everything is configured properly! :)"))
 				default:
 					msg.Text = bot.printHelp()
 				}
@@ -61,10 +70,14 @@ func (bot *Telegram) Start() {
 func (bot *Telegram) printHelp() string {
 	var b strings.Builder
 
-	b.WriteString("Available commands:\n\n")
-	b.WriteString("/help - Get this help\n")
-	b.WriteString("/channel - Return the channel id\n")
-	b.WriteString("/check - Send a message using the channel id from the configuration file (to verify it's working)\n")
+	b.WriteString(bot.printer.Sprintf("Available commands:"))
+	b.WriteString("\n\n")
+	b.WriteString(bot.printer.Sprintf("/help - Get this help"))
+	b.WriteString("\n")
+	b.WriteString(bot.printer.Sprintf("/channel - Return the channel id"))
+	b.WriteString("\n")
+	b.WriteString(bot.printer.Sprintf("/check - Send a message using the channel id from the configuration file (to verify it's working)"))
+	b.WriteString("\n")
 
 	return b.String()
 }
@@ -75,10 +88,35 @@ func (bot *Telegram) rawSend(msg tgbotapi.MessageConfig) error {
 }
 
 func (bot *Telegram) Send(text string) error {
+	return bot.SendMessage(Message{Text: text})
+}
+
+// SendMessage sends the message to the configured channel, disabling the notification
+// sound for silent messages
+func (bot *Telegram) SendMessage(m Message) error {
 	if bot.channelId == 0 {
 		return fmt.Errorf("empty channel id, please use the /c command to obtain the value from the bot")
 	}
-	msg := tgbotapi.NewMessage(bot.channelId, text)
+	msg := tgbotapi.NewMessage(bot.channelId, m.Text)
+	msg.DisableNotification = m.Silent
 	_, err := bot.bot.Send(msg)
+
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		return &RateLimitError{RetryAfter: time.Duration(tgErr.RetryAfter) * time.Second, Err: err}
+	}
+	if errors.As(err, &tgErr) && isPermanentStatus(tgErr.Code) {
+		return &PermanentError{Err: err}
+	}
+
 	return err
 }
+
+// Check verifies the bot token calling the Telegram getMe API
+func (bot *Telegram) Check() error {
+	if _, err := bot.bot.GetMe(); err != nil {
+		return fmt.Errorf("getMe failed: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package notifier
+
+import (
+	"sort"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// Bounty vouchers are lost when the ship is destroyed: the bounties earned and not redeemed
+// yet are tracked by faction, as vouchers are redeemed one faction at a time.
+
+// BountiesCfg defines the alerts about the unclaimed bounties
+type BountiesCfg struct {
+	Thresholds []int64 // credits of unclaimed bounties above which a notification is sent, once per threshold
+}
+
+// factionAmount is the unclaimed bounties of a faction, as seen by the templates
+type factionAmount struct {
+	Faction string
+	Amount  int64
+}
+
+// addBounty adds the rewards of the bounty to the unclaimed ones of each faction
+func (e *Notifier) addBounty(j *journal.Bounty) {
+	if len(j.Rewards) == 0 {
+		e.unclaimed[""] += j.TotalReward
+		return
+	}
+
+	for _, r := range j.Rewards {
+		e.unclaimed[r.Faction] += r.Reward
+	}
+}
+
+// redeemVoucher subtracts the redeemed amounts from the bounties of each faction. When the
+// factions aren't listed, e.g. at an interstellar factor, the amount is subtracted from any
+// faction.
+func (e *Notifier) redeemVoucher(j *journal.RedeemVoucher) {
+	if j.Type != "bounty" {
+		return
+	}
+
+	if len(j.Factions) == 0 {
+		amount := j.Amount
+		// The amount paid by a broker is net of its percentage
+		if j.BrokerPercentage > 0 && j.BrokerPercentage < 100 {
+			amount = int64(float64(amount) / (1 - j.BrokerPercentage/100))
+		}
+		e.subtractUnclaimed(j.Faction, amount)
+	}
+	for _, f := range j.Factions {
+		e.subtractUnclaimed(f.Faction, f.Amount)
+	}
+
+	e.unclaimedThreshold = e.cfg.Bounties.threshold(e.unclaimedTotal())
+}
+
+// subtractUnclaimed subtracts the amount from the bounties of the faction, or of the factions
+// in name order when it's empty, never going below 0
+func (e *Notifier) subtractUnclaimed(faction string, amount int64) {
+	factions := []string{faction}
+	if faction == "" {
+		factions = make([]string, 0, len(e.unclaimed))
+		for f := range e.unclaimed {
+			factions = append(factions, f)
+		}
+		sort.Strings(factions)
+	}
+
+	for _, f := range factions {
+		if amount <= 0 {
+			return
+		}
+
+		if e.unclaimed[f] > amount {
+			e.unclaimed[f] -= amount
+			return
+		}
+		amount -= e.unclaimed[f]
+		delete(e.unclaimed, f)
+	}
+}
+
+// loseBounties clears the unclaimed bounties, lost with the ship
+func (e *Notifier) loseBounties() {
+	e.unclaimed = make(map[string]int64)
+	e.unclaimedThreshold = 0
+}
+
+func (e *Notifier) unclaimedTotal() int64 {
+	var total int64
+	for _, amount := range e.unclaimed {
+		total += amount
+	}
+
+	return total
+}
+
+// unclaimedFactions returns the unclaimed bounties by faction, the highest first
+func (e *Notifier) unclaimedFactions() []factionAmount {
+	factions := make([]factionAmount, 0, len(e.unclaimed))
+	for f, amount := range e.unclaimed {
+		if f == "" {
+			f = "?"
+		}
+		factions = append(factions, factionAmount{Faction: f, Amount: amount})
+	}
+	sort.Slice(factions, func(i, j int) bool {
+		if factions[i].Amount != factions[j].Amount {
+			return factions[i].Amount > factions[j].Amount
+		}
+		return factions[i].Faction < factions[j].Faction
+	})
+
+	return factions
+}
+
+// threshold returns the highest threshold reached by the amount, or 0
+func (c BountiesCfg) threshold(amount int64) int64 {
+	var reached int64
+	for _, t := range c.Thresholds {
+		if t > 0 && amount >= t && t > reached {
+			reached = t
+		}
+	}
+
+	return reached
+}
+
+// checkUnclaimed notifies when the unclaimed bounties cross a new threshold
+func (e *Notifier) checkUnclaimed(ev journal.Event, skipNotify bool) error {
+	total := e.unclaimedTotal()
+	t := e.cfg.Bounties.threshold(total)
+	if t <= e.unclaimedThreshold {
+		return nil
+	}
+	e.unclaimedThreshold = t
+
+	values := map[string]interface{}{
+		"Unclaimed": total,
+		"Threshold": t,
+		"Factions":  e.unclaimedFactions(),
+	}
+
+	return e.notifyTemplateWith(unclaimedTemplate, categoryKills, ev, values, skipNotify)
+}
+
+func redeemVoucherEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	e.redeemVoucher(ev.(*journal.RedeemVoucher))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_unclaimedBounties(t *testing.T) {
+	bounty := func(rewards ...journal.BountyReward) *journal.Bounty {
+		j := &journal.Bounty{Rewards: rewards}
+		for _, r := range rewards {
+			j.TotalReward += r.Reward
+		}
+		return j
+	}
+
+	tests := []struct {
+		name          string
+		fn            eventFn
+		ev            journal.Event
+		wantMsg       string
+		wantUnclaimed int64
+	}{
+		{
+			name:          "below the thresholds",
+			fn:            bountyEvent,
+			ev:            bounty(journal.BountyReward{Faction: "Ngalinn Crimson Boys", Reward: 600000}),
+			wantUnclaimed: 600000,
+		},
+		{
+			name:          "first threshold",
+			fn:            bountyEvent,
+			ev:            bounty(journal.BountyReward{Faction: "Ngalinn Crimson Boys", Reward: 300000}, journal.BountyReward{Faction: "Pilots' Federation", Reward: 200000}),
+			wantMsg:       "Unclaimed bounties: 1,100,000 CR, over 1,000,000 CR, time to cash them in\n- Ngalinn Crimson Boys: 900,000 CR\n- Pilots' Federation: 200,000 CR",
+			wantUnclaimed: 1100000,
+		},
+		{
+			name:          "same threshold",
+			fn:            bountyEvent,
+			ev:            bounty(journal.BountyReward{Faction: "Pilots' Federation", Reward: 400000}),
+			wantUnclaimed: 1500000,
+		},
+		{
+			name:          "partially redeemed",
+			fn:            redeemVoucherEvent,
+			ev:            &journal.RedeemVoucher{Type: "bounty", Amount: 400000, Factions: []journal.VoucherFaction{{Faction: "Ngalinn Crimson Boys", Amount: 400000}}},
+			wantUnclaimed: 1100000,
+		},
+		{
+			name:          "faction redeemed",
+			fn:            redeemVoucherEvent,
+			ev:            &journal.RedeemVoucher{Type: "bounty", Amount: 500000, Factions: []journal.VoucherFaction{{Faction: "Ngalinn Crimson Boys", Amount: 500000}}},
+			wantUnclaimed: 600000,
+		},
+		{
+			name:          "first threshold again",
+			fn:            bountyEvent,
+			ev:            bounty(journal.BountyReward{Faction: "Ngalinn Crimson Boys", Reward: 500000}),
+			wantMsg:       "Unclaimed bounties: 1,100,000 CR, over 1,000,000 CR, time to cash them in\n- Pilots' Federation: 600,000 CR\n- Ngalinn Crimson Boys: 500,000 CR",
+			wantUnclaimed: 1100000,
+		},
+		{
+			name:          "second threshold",
+			fn:            bountyEvent,
+			ev:            bounty(journal.BountyReward{Faction: "Ngalinn Crimson Boys", Reward: 5000000}),
+			wantMsg:       "Unclaimed bounties: 6,100,000 CR, over 5,000,000 CR, time to cash them in\n- Ngalinn Crimson Boys: 5,500,000 CR\n- Pilots' Federation: 600,000 CR",
+			wantUnclaimed: 6100000,
+		},
+		{
+			name:          "redeemed at a broker",
+			fn:            redeemVoucherEvent,
+			ev:            &journal.RedeemVoucher{Type: "bounty", Amount: 750000, BrokerPercentage: 25},
+			wantUnclaimed: 5100000,
+		},
+		{
+			name:    "died",
+			fn:      diedEvent,
+			ev:      &journal.Died{Header: journal.Header{Event: journal.DiedEvent}},
+			wantMsg: "Your ship has been destroyed, 5,100,000 CR of unclaimed bounties lost",
+		},
+	}
+
+	n := &Notifier{cfg: &Cfg{Bounties: BountiesCfg{Thresholds: []int64{5000000, 1000000}}}}
+	n.initCounters()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := tt.fn(n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+			if got := n.sessionStats().Unclaimed; got != tt.wantUnclaimed {
+				t.Fatalf("wantUnclaimed: %d, got: %d", tt.wantUnclaimed, got)
+			}
+		})
+	}
+}
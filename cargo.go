@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"errors"
+	"io/fs"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// Pirates attack only ships carrying some cargo: when the bait is ejected, stolen with hatch
+// breakers or was never bought, the session silently stops earning.
+
+// CargoCfg defines the alerts about the cargo used as bait for the pirates
+type CargoCfg struct {
+	Min    int  // tons of cargo below which the cargo is low, 0 to disable the alert
+	Undock bool // warn when undocking with an empty cargo hold
+}
+
+// bait returns the tons of cargo enough as bait: the minimum cargo when set, otherwise any cargo
+func (c CargoCfg) bait() int {
+	if c.Min > 0 {
+		return c.Min
+	}
+
+	return 1
+}
+
+// loadCargo sets the cargo from the Cargo.json file, when the journal hasn't reported it yet
+func (e *Notifier) loadCargo() {
+	if e.cargo >= 0 {
+		return
+	}
+
+	if cargo := e.readCargo(); cargo != nil {
+		e.cargo = cargo.Count
+	}
+}
+
+// readCargo returns the cargo of the ship in the Cargo.json file, or nil
+func (e *Notifier) readCargo() *journal.Cargo {
+	cargo, err := journal.ReadCargo(e.journalPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Infoln("[ERROR]", err)
+		}
+		return nil
+	}
+	if cargo.Vessel != "" && cargo.Vessel != "Ship" {
+		return nil
+	}
+
+	return cargo
+}
+
+// setCargo updates the tons of cargo of the ship, notifying when it goes below the minimum
+func (e *Notifier) setCargo(count int, ev journal.Event, skipNotify bool) error {
+	if count < 0 {
+		count = 0
+	}
+	e.cargo = count
+	printLog(ev, "Cargo:", count)
+
+	if e.cfg.Cargo.Min <= 0 {
+		return nil
+	}
+	if count >= e.cfg.Cargo.Min {
+		e.cargoLow = false
+		return nil
+	}
+	if e.cargoLow {
+		return nil
+	}
+	e.cargoLow = true
+
+	cargo := &journal.Cargo{
+		Header: journal.Header{Timestamp: ev.Time(), Event: journal.CargoEvent},
+		Vessel: "Ship",
+		Count:  count,
+	}
+
+	return e.notifyTemplate(cargoLowTemplate, categoryCargo, cargo, skipNotify)
+}
+
+func cargoEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.Cargo)
+	if j.Vessel != "" && j.Vessel != "Ship" {
+		return nil
+	}
+
+	// Without the inventory, the game writes the full cargo to Cargo.json along with the event
+	count := j.Count
+	if j.Inventory == nil {
+		if cargo := e.readCargo(); cargo != nil && !cargo.Timestamp.Before(j.Timestamp) {
+			count = cargo.Count
+		}
+	}
+
+	return e.setCargo(count, j, skipNotify)
+}
+
+// ejectCargoEvent and cargoTransferEvent keep the cargo up to date, the game also writes a
+// Cargo event with the new total right after them
+func ejectCargoEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.EjectCargo)
+	if e.cargo < 0 {
+		return nil
+	}
+
+	return e.setCargo(e.cargo-j.Count, j, skipNotify)
+}
+
+func cargoTransferEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.CargoTransfer)
+	if e.cargo < 0 {
+		return nil
+	}
+
+	count := e.cargo
+	for _, t := range j.Transfers {
+		if t.Direction == "toship" {
+			count += t.Count
+		} else {
+			count -= t.Count
+		}
+	}
+
+	return e.setCargo(count, j, skipNotify)
+}
+
+// emptyHold returns whether the ship has no cargo and the empty cargo hold warning is enabled
+func (e *Notifier) emptyHold() bool {
+	return e.cfg.Cargo.Undock && e.cargo == 0
+}
+
+func emptyCargoEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if !e.emptyHold() {
+		return nil
+	}
+
+	return e.notifyTemplate(emptyCargoTemplate, categoryCargo, ev, skipNotify)
+}
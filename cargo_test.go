@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_cargoEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        eventFn
+		ev        journal.Event
+		wantCargo int
+		wantMsg   string
+	}{
+		{name: "bait loaded", fn: cargoEvent, ev: &journal.Cargo{Vessel: "Ship", Count: 2}, wantCargo: 2},
+		{name: "SRV cargo", fn: cargoEvent, ev: &journal.Cargo{Vessel: "SRV", Count: 0}, wantCargo: 2},
+		{name: "ejected", fn: ejectCargoEvent, ev: &journal.EjectCargo{Type: "gold", Count: 1}, wantCargo: 1},
+		{name: "stolen", fn: cargoEvent, ev: &journal.Cargo{Vessel: "Ship", Count: 0}, wantCargo: 0, wantMsg: "Cargo is down to 0 t, pirates may stop attacking"},
+		{name: "already reported", fn: cargoEvent, ev: &journal.Cargo{Vessel: "Ship", Count: 0}, wantCargo: 0},
+		{name: "undocked empty", fn: undockedEvent, ev: &journal.Undocked{StationName: "Ford Terminal"}, wantCargo: 0, wantMsg: "You undocked from Ford Terminal with an empty cargo hold, pirates won't attack"},
+		{
+			name:      "transferred to ship",
+			fn:        cargoTransferEvent,
+			ev:        &journal.CargoTransfer{Transfers: []journal.Transfer{{Type: "gold", Count: 3, Direction: "toship"}}},
+			wantCargo: 3,
+		},
+		{name: "undocked", fn: undockedEvent, ev: &journal.Undocked{StationName: "Ford Terminal"}, wantCargo: 3},
+		{name: "low again", fn: cargoEvent, ev: &journal.Cargo{Vessel: "Ship", Count: 0}, wantCargo: 0, wantMsg: "Cargo is down to 0 t, pirates may stop attacking"},
+	}
+
+	n := &Notifier{cfg: &Cfg{Cargo: CargoCfg{Min: 1, Undock: true}}, cargo: -1}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := tt.fn(n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if n.cargo != tt.wantCargo {
+				t.Fatalf("wantCargo: %d, got: %d", tt.wantCargo, n.cargo)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func TestNotifier_loadCargo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, journal.CargoFile),
+		`{ "timestamp":"2024-03-09T20:14:02Z", "event":"Cargo", "Vessel":"Ship", "Count":4, "Inventory":[ { "Name":"gold", "Count":4, "Stolen":0 } ] }`,
+		time.Now())
+
+	n := &Notifier{journalPath: dir, cargo: -1}
+	n.loadCargo()
+	if n.cargo != 4 {
+		t.Fatalf("want: 4, got: %d", n.cargo)
+	}
+
+	// The cargo reported by the journal is more recent
+	n = &Notifier{journalPath: dir, cargo: 1}
+	n.loadCargo()
+	if n.cargo != 1 {
+		t.Fatalf("want: 1, got: %d", n.cargo)
+	}
+}
+
+func Test_cargoEventReadsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, journal.CargoFile)
+	writeFile(t, path, `{ "timestamp":"2024-03-09T20:14:02Z", "event":"Cargo", "Vessel":"Ship", "Count":4, "Inventory":[ { "Name":"gold", "Count":4, "Stolen":0 } ] }`, time.Now())
+
+	n := &Notifier{cfg: &Cfg{Cargo: CargoCfg{Min: 1}}, journalPath: dir, cargo: -1}
+	n.loadCargo()
+
+	// The cargo changes after the startup, the event has no inventory
+	writeFile(t, path, `{ "timestamp":"2024-03-09T21:30:00Z", "event":"Cargo", "Vessel":"Ship", "Count":6, "Inventory":[ { "Name":"gold", "Count":6, "Stolen":0 } ] }`, time.Now())
+	ts := time.Date(2024, 3, 9, 21, 30, 0, 0, time.UTC)
+	bot := &mockBot{}
+	n.bot = bot
+	if err := cargoEvent(n, &journal.Cargo{Header: journal.Header{Timestamp: ts}, Vessel: "Ship"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if n.cargo != 6 || bot.sentMsg != "" {
+		t.Fatalf("want 6 t without messages, got: %d t, %q", n.cargo, bot.sentMsg)
+	}
+
+	// A file older than the event is ignored
+	if err := cargoEvent(n, &journal.Cargo{Header: journal.Header{Timestamp: ts.Add(time.Minute)}, Vessel: "Ship", Count: 2}, false); err != nil {
+		t.Fatal(err)
+	}
+	if n.cargo != 2 {
+		t.Fatalf("want: 2, got: %d", n.cargo)
+	}
+}
@@ -0,0 +1,234 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/tommyblue/ED-AFK-Notifier/bots"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// CheckResult is the outcome of a single configuration check
+type CheckResult struct {
+	Section string // config section the check refers to, e.g. "journal"
+	Name    string // short description of what has been checked
+	Err     error  // nil when the check passed
+}
+
+// Passed returns whether the check succeeded
+func (r CheckResult) Passed() bool {
+	return r.Err == nil
+}
+
+// CheckConfig validates every section of the configuration and, when the selected
+// notification service is properly configured, verifies the connection to its backend
+func CheckConfig(cfg *Cfg) []CheckResult {
+	var results []CheckResult
+
+	results = append(results, checkJournal(cfg)...)
+	results = append(results, checkTemplates(cfg)...)
+	results = append(results, checkSchedule(cfg))
+	results = append(results, checkThrottle(cfg))
+	results = append(results, checkThreat(cfg))
+	results = append(results, checkReadiness(cfg))
+	results = append(results, checkRules(cfg))
+	if cfg.Outbox != nil {
+		results = append(results, checkOutbox(cfg))
+	}
+
+	var serviceResults []CheckResult
+	switch cfg.NotificationService {
+	case "telegram":
+		serviceResults = checkTelegram(cfg)
+	case "gotify":
+		serviceResults = checkGotify(cfg)
+	default:
+		serviceResults = []CheckResult{{
+			Section: "notification",
+			Name:    "service",
+			Err:     fmt.Errorf("unknown notification service: %q", cfg.NotificationService),
+		}}
+	}
+	results = append(results, serviceResults...)
+
+	// Don't try to connect to the backend if its configuration is already known to be wrong
+	if failed(serviceResults) {
+		return results
+	}
+
+	return append(results, checkBackend(cfg))
+}
+
+func checkJournal(cfg *Cfg) []CheckResult {
+	var results []CheckResult
+	paths := make(map[string]bool)
+	for _, src := range cfg.journalSources() {
+		results = append(results, checkJournalPath(src.Path, paths, len(cfg.Journals) > 0)...)
+	}
+
+	return results
+}
+
+// checkJournalPath checks a journal directory, which must not be in paths already. With
+// more journals, the names of the checks include the directory.
+func checkJournalPath(journalPath string, paths map[string]bool, named bool) []CheckResult {
+	pathCheck := CheckResult{Section: "journal", Name: "path exists"}
+	filesCheck := CheckResult{Section: "journal", Name: "path contains Journal*.log files"}
+
+	path, err := ResolveJournalPath(journalPath)
+	if err != nil {
+		pathCheck.Err = err
+		return []CheckResult{pathCheck}
+	}
+	if named {
+		pathCheck.Name = fmt.Sprintf("path %s exists", path)
+		filesCheck.Name = fmt.Sprintf("path %s contains Journal*.log files", path)
+	}
+	if paths[path] {
+		pathCheck.Err = fmt.Errorf("%s is configured more than once", path)
+		return []CheckResult{pathCheck}
+	}
+	paths[path] = true
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		pathCheck.Err = err
+		return []CheckResult{pathCheck}
+	}
+	if !fi.IsDir() {
+		pathCheck.Err = fmt.Errorf("%s is not a directory", path)
+		return []CheckResult{pathCheck}
+	}
+
+	if _, err := journal.LatestFile(path); err != nil {
+		filesCheck.Err = err
+	}
+
+	return []CheckResult{pathCheck, filesCheck}
+}
+
+func checkTemplates(cfg *Cfg) []CheckResult {
+	langCheck := CheckResult{Section: "notification", Name: "language is supported"}
+	lang, err := parseLanguage(cfg.Language)
+	if err != nil {
+		langCheck.Err = err
+		return []CheckResult{langCheck}
+	}
+
+	templatesCheck := CheckResult{Section: "templates", Name: "templates are valid"}
+	_, templatesCheck.Err = parseTemplates(cfg.Templates, lang)
+
+	return []CheckResult{langCheck, templatesCheck}
+}
+
+func checkSchedule(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: "schedule", Name: "schedule is valid"}
+	_, r.Err = newSchedule(cfg.Schedule)
+
+	return r
+}
+
+func checkThrottle(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: "throttle", Name: "cooldowns are valid"}
+	_, r.Err = newThrottle(cfg.Throttle)
+
+	return r
+}
+
+func checkThreat(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: "threat", Name: "threat settings are valid"}
+	_, r.Err = newThreat(cfg.Threat)
+
+	return r
+}
+
+func checkReadiness(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: "readiness", Name: "readiness checks are valid"}
+	r.Err = cfg.Readiness.validate()
+
+	return r
+}
+
+func checkRules(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: "rules", Name: "rules are valid"}
+
+	// The language has already been checked, fall back to the default one on errors
+	lang, _ := parseLanguage(cfg.Language)
+	_, r.Err = newRules(cfg.Rules, lang)
+
+	return r
+}
+
+func checkOutbox(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: "outbox", Name: "outbox is valid"}
+	_, r.Err = newOutbox(*cfg.Outbox)
+
+	return r
+}
+
+func checkTelegram(cfg *Cfg) []CheckResult {
+	token := CheckResult{Section: "telegram", Name: "token is set"}
+	if cfg.TelegramToken == "" {
+		token.Err = errors.New("token is empty, create a bot with BotFather")
+	}
+
+	channel := CheckResult{Section: "telegram", Name: "channelId is set"}
+	if cfg.TelegramChannelId == 0 {
+		channel.Err = errors.New("channelId is empty, send the /channel command to the bot to obtain it")
+	}
+
+	return []CheckResult{token, channel}
+}
+
+func checkGotify(cfg *Cfg) []CheckResult {
+	u := CheckResult{Section: "gotify", Name: "url is valid"}
+	if cfg.GotifyURL == "" {
+		u.Err = errors.New("url is empty")
+	} else if parsed, err := url.Parse(cfg.GotifyURL); err != nil {
+		u.Err = err
+	} else if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		u.Err = fmt.Errorf("%q is not an http(s) URL", cfg.GotifyURL)
+	}
+
+	token := CheckResult{Section: "gotify", Name: "token is set"}
+	if cfg.GotifyToken == "" {
+		token.Err = errors.New("token is empty, create an application in Gotify to obtain it")
+	}
+
+	priority := CheckResult{Section: "gotify", Name: "priority is in range"}
+	if cfg.GotifyPriority < 1 || cfg.GotifyPriority > 10 {
+		priority.Err = fmt.Errorf("priority must be between 1 and 10, got %d", cfg.GotifyPriority)
+	}
+
+	return []CheckResult{u, token, priority}
+}
+
+func checkBackend(cfg *Cfg) CheckResult {
+	r := CheckResult{Section: cfg.NotificationService, Name: "backend is reachable"}
+
+	// The language has already been checked, fall back to the default one on errors
+	lang, _ := parseLanguage(cfg.Language)
+	bot, err := newBot(cfg, lang)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	if c, ok := bot.(bots.Checker); ok {
+		r.Err = c.Check()
+	}
+
+	return r
+}
+
+func failed(results []CheckResult) bool {
+	for _, r := range results {
+		if !r.Passed() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import "testing"
+
+func Test_checkGotify(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority int
+		wantErr  bool
+	}{
+		{name: "lowest", priority: 1},
+		{name: "default", priority: 5},
+		{name: "highest", priority: 10},
+		{name: "zero", priority: 0, wantErr: true},
+		{name: "negative", priority: -1, wantErr: true},
+		{name: "too high", priority: 11, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Cfg{GotifyURL: "https://gotify.example.com", GotifyToken: "token", GotifyPriority: tt.priority}
+
+			results := checkGotify(cfg)
+			for _, r := range results[:2] {
+				if r.Err != nil {
+					t.Fatalf("%s: want: nil, got: %v", r.Name, r.Err)
+				}
+			}
+			if err := results[2].Err; (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %t, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
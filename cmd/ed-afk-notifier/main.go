@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/arl/statsviz"
 
@@ -18,7 +22,8 @@ var statsAddr = "localhost:6060"
 // Use `-ldflags "-X main.version=someversion"` when building baker to set this value
 var version = "-- unknown --"
 var flagVersion = flag.Bool("version", false, "print version number")
-var flagStats = flag.Bool("stats", false, fmt.Sprintf("show stats at %s", statsAddr))
+var flagStats = flag.Bool("stats", false, fmt.Sprintf("show stats at %s (and metrics at /debug/vars)", statsAddr))
+var flagConfig = flag.String("config", "", "path to the config file (default: search config.toml in the current dir, the user config dir and the binary dir)")
 
 func init() {
 	log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
@@ -46,6 +51,108 @@ func main() {
 		log.Fatalf("Cannot read config: %v", err)
 	}
 
+	cfg, decoded := buildConfig()
+
+	if viper.GetBool("journal.debug") {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	switch flag.Arg(0) {
+	case "":
+		// no command, run the notifier
+	case "check-config":
+		os.Exit(checkConfig(cfg, decoded))
+	default:
+		log.Fatalf("Unknown command: %s", flag.Arg(0))
+	}
+
+	// A section that can't be decoded would silently disable its feature
+	for _, r := range decoded {
+		if !r.Passed() {
+			log.Fatalf("Invalid config: %v", r.Err)
+		}
+	}
+
+	logConfig(cfg)
+
+	notifier, err := notifier.New(cfg)
+
+	if err != nil {
+		log.Fatalf("Cannot initialize the notifier: %v", err)
+	}
+
+	notifier.SendStartupNotification(version)
+	notifier.Start()
+}
+
+// setupConfig reads the config file, looking for it in the path set with the -config flag or
+// searching config.toml in configPaths. Every key can be overridden by an environment variable
+// named after the key, prefixed by ED_AFK_ (e.g. telegram.token -> ED_AFK_TELEGRAM_TOKEN), so
+// the config file is optional when everything is set in the environment.
+func setupConfig() error {
+	viper.SetDefault("journal.critical_hull", 25)
+	viper.SetDefault("journal.conflict_zones", true)
+	viper.SetDefault("telegram.rate", 1) // Telegram allows about one message per second in a chat
+	viper.SetDefault("telegram.burst", 1)
+	viper.SetDefault("outbox.enabled", true)
+	viper.SetDefault("outbox.collapse", []string{"kills", "shields"})
+
+	viper.SetEnvPrefix("ED_AFK")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if *flagConfig != "" {
+		viper.SetConfigFile(*flagConfig)
+		if err := viper.ReadInConfig(); err != nil {
+			return err
+		}
+		log.Infof("Using config file: %s", viper.ConfigFileUsed())
+		return nil
+	}
+
+	viper.SetConfigName("config")
+	viper.SetConfigType("toml")
+	for _, p := range configPaths() {
+		viper.AddConfigPath(p)
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return err
+		}
+
+		log.Warnf("No config.toml found in %s, using environment variables only", strings.Join(configPaths(), ", "))
+		return nil
+	}
+	log.Infof("Using config file: %s", viper.ConfigFileUsed())
+
+	return nil
+}
+
+// configPaths returns the directories searched for config.toml, in order of precedence:
+// the current directory, the user config directory (e.g. $XDG_CONFIG_HOME/ed-afk-notifier)
+// and the directory of the binary
+func configPaths() []string {
+	paths := []string{"."}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "ed-afk-notifier"))
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		paths = append(paths, filepath.Dir(exe))
+	}
+
+	return paths
+}
+
+// buildConfig returns the configuration and the outcome of decoding its sections made of tables,
+// e.g. [[rules]]
+func buildConfig() (*notifier.Cfg, []notifier.CheckResult) {
 	// Get notification service from config, default to telegram for backward compatibility
 	service := viper.GetString("notification.service")
 	if service == "" {
@@ -54,11 +161,112 @@ func main() {
 
 	cfg := &notifier.Cfg{
 		NotificationService: service,
+		Language:            viper.GetString("notification.language"),
 		JournalPath:         viper.GetString("journal.path"),
 		FighterNotifs:       viper.GetBool("journal.fighter"),
 		ShieldsNotifs:       viper.GetBool("journal.shields"),
 		KillsNotifs:         viper.GetBool("journal.kills"),
 		KillsSilentNotifs:   viper.GetBool("journal.silent_kills"),
+		ConflictZones:       viper.GetBool("journal.conflict_zones"),
+		CriticalHull:        viper.GetFloat64("journal.critical_hull") / 100,
+		Templates:           make(map[string]string),
+		QueueSize:           viper.GetInt("queue.size"),
+		QueueWorkers:        viper.GetInt("queue.workers"),
+		SendTimeout:         time.Duration(viper.GetInt("queue.timeout")) * time.Second,
+		Coalesce:            time.Duration(viper.GetInt("queue.coalesce")) * time.Second,
+	}
+
+	// The keys of the tables named by the user are read one by one, so that the environment
+	// can override those in the config file
+	for name := range viper.GetStringMap("templates") {
+		cfg.Templates[name] = viper.GetString("templates." + name)
+	}
+
+	if viper.GetBool("outbox.enabled") {
+		cfg.Outbox = &notifier.OutboxCfg{
+			Path:     viper.GetString("outbox.path"),
+			Expiry:   make(map[string]time.Duration),
+			Collapse: viper.GetStringSlice("outbox.collapse"),
+		}
+		for category := range viper.GetStringMap("outbox.expiry") {
+			cfg.Outbox.Expiry[category] = time.Duration(viper.GetInt("outbox.expiry."+category)) * time.Minute
+		}
+	}
+
+	cfg.Throttle = notifier.ThrottleCfg{
+		DedupWindow:     time.Duration(viper.GetInt("throttle.dedup_window")) * time.Second,
+		Cooldowns:       make(map[string]time.Duration),
+		IncludeCritical: viper.GetBool("throttle.include_critical"),
+	}
+	for event := range viper.GetStringMap("throttle.cooldowns") {
+		cfg.Throttle.Cooldowns[event] = time.Duration(viper.GetInt("throttle.cooldowns."+event)) * time.Second
+	}
+
+	cfg.Threat = notifier.ThreatCfg{
+		MinRank:     viper.GetString("threat.min_rank"),
+		Ships:       viper.GetStringSlice("threat.ships"),
+		Players:     viper.GetBool("threat.players"),
+		UnderAttack: viper.GetBool("threat.under_attack"),
+		Scans:       viper.GetBool("threat.scans"),
+	}
+
+	cfg.Cargo = notifier.CargoCfg{
+		Min:    viper.GetInt("cargo.min"),
+		Undock: viper.GetBool("cargo.undock_warning"),
+	}
+
+	cfg.Heat = notifier.HeatCfg{
+		Cooldown: time.Duration(viper.GetInt("heat.cooldown")) * time.Second,
+		Escalate: time.Duration(viper.GetInt("heat.escalate")) * time.Second,
+	}
+
+	cfg.Readiness = notifier.ReadinessCfg{
+		Checks: viper.GetStringSlice("readiness.checks"),
+	}
+
+	for _, t := range viper.GetIntSlice("bounties.thresholds") {
+		cfg.Bounties.Thresholds = append(cfg.Bounties.Thresholds, int64(t))
+	}
+
+	var decoded []notifier.CheckResult
+	decoded = append(decoded, unmarshalSection("schedule", &cfg.Schedule))
+
+	// Cooldowns are in seconds in the config file, like the throttle ones
+	var rules []struct {
+		Name     string
+		Event    string
+		When     string
+		Message  string
+		Severity string
+		Cooldown int
+	}
+	decoded = append(decoded, unmarshalSection("rules", &rules))
+	for _, r := range rules {
+		cfg.Rules = append(cfg.Rules, notifier.RuleCfg{
+			Name:     r.Name,
+			Event:    r.Event,
+			When:     r.When,
+			Message:  r.Message,
+			Severity: r.Severity,
+			Cooldown: time.Duration(r.Cooldown) * time.Second,
+		})
+	}
+
+	// Journals of more commanders, each optionally with its own chat
+	var journals []struct {
+		Path      string
+		Commander string
+		ChannelId int64  `mapstructure:"telegram_channel"`
+		Token     string `mapstructure:"gotify_token"`
+	}
+	decoded = append(decoded, unmarshalSection("journals", &journals))
+	for _, j := range journals {
+		cfg.Journals = append(cfg.Journals, notifier.JournalCfg{
+			Path:              j.Path,
+			Commander:         j.Commander,
+			TelegramChannelId: j.ChannelId,
+			GotifyToken:       j.Token,
+		})
 	}
 
 	// Set service-specific configuration
@@ -66,46 +274,108 @@ func main() {
 	case "telegram":
 		cfg.TelegramToken = viper.GetString("telegram.token")
 		cfg.TelegramChannelId = viper.GetInt64("telegram.channelId")
+		cfg.TelegramRate = viper.GetFloat64("telegram.rate")
+		cfg.TelegramBurst = viper.GetInt("telegram.burst")
 	case "gotify":
 		cfg.GotifyURL = viper.GetString("gotify.url")
 		cfg.GotifyToken = viper.GetString("gotify.token")
 		cfg.GotifyTitle = viper.GetString("gotify.title")
-		cfg.GotifyPriority = viper.GetInt("gotify.priority")
-	default:
-		log.Fatalf("Unknown notification service: %s", service)
+		// An explicit 0 is reported as out of range by check-config
+		cfg.GotifyPriority = 5
+		if viper.IsSet("gotify.priority") {
+			cfg.GotifyPriority = viper.GetInt("gotify.priority")
+		}
+		cfg.GotifyRate = viper.GetFloat64("gotify.rate")
+		cfg.GotifyBurst = viper.GetInt("gotify.burst")
 	}
 
-	if viper.GetBool("journal.debug") {
-		log.SetLevel(log.DebugLevel)
-	}
+	return cfg, decoded
+}
 
-	logConfig(cfg)
+// unmarshalSection decodes a section of the config file into v
+func unmarshalSection(section string, v interface{}) notifier.CheckResult {
+	r := notifier.CheckResult{Section: section, Name: "section can be decoded"}
+	if err := viper.UnmarshalKey(section, v); err != nil {
+		r.Err = fmt.Errorf("cannot read the %s config: %v", section, err)
+	}
 
-	notifier, err := notifier.New(cfg)
+	return r
+}
 
-	if err != nil {
-		log.Fatalf("Cannot initialize the notifier: %v", err)
+// checkConfig prints a pass/fail report of the configuration, including the outcome of
+// decoding its sections, returning the exit code
+func checkConfig(cfg *notifier.Cfg, decoded []notifier.CheckResult) int {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		configFile = "environment variables only"
 	}
+	fmt.Printf("Checking configuration (%s)\n\n", configFile)
 
-	notifier.SendStartupNotification(version)
-	notifier.Start()
-}
+	code := 0
+	for _, r := range append(decoded, notifier.CheckConfig(cfg)...) {
+		if r.Passed() {
+			fmt.Printf("[PASS] %s: %s\n", r.Section, r.Name)
+			continue
+		}
 
-func setupConfig() error {
-	viper.SetConfigName("config")
-	viper.SetConfigType("toml")
-	viper.AddConfigPath(".")
+		code = 1
+		fmt.Printf("[FAIL] %s: %s: %v\n", r.Section, r.Name, r.Err)
+	}
+
+	if code == 0 {
+		fmt.Println("\nConfiguration is valid")
+	} else {
+		fmt.Println("\nConfiguration has errors")
+	}
 
-	return viper.ReadInConfig()
+	return code
 }
 
 func logConfig(cfg *notifier.Cfg) {
 	log.Infof("Config:")
 	log.Infof("  Notification service: %s", cfg.NotificationService)
+	if cfg.Language != "" {
+		log.Infof("  Notification language: %s", cfg.Language)
+	}
 	log.Infof("  Notify fighter status: %t", cfg.FighterNotifs)
 	log.Infof("  Notify shields status: %t", cfg.ShieldsNotifs)
 	log.Infof("  Notify on kills: %t (silent: %t)", cfg.KillsNotifs, cfg.KillsSilentNotifs)
-	log.Infof("  Journal file path: %s", cfg.JournalPath)
+	log.Infof("  Critical hull integrity: %.0f%%", cfg.CriticalHull*100)
+	if cfg.Outbox != nil {
+		path := cfg.Outbox.Path
+		if path == "" {
+			path = notifier.DefaultOutboxPath()
+		}
+		log.Infof("  Outbox file: %s", path)
+	}
+	if n := len(cfg.Schedule.Windows); n > 0 {
+		log.Infof("  Schedule windows: %d", n)
+	}
+	if cfg.Threat.MinRank != "" || len(cfg.Threat.Ships) > 0 || cfg.Threat.Players {
+		log.Infof("  Dangerous contacts: rank %s or above, ships %v, players: %t", cfg.Threat.MinRank, cfg.Threat.Ships, cfg.Threat.Players)
+	}
+	if cfg.Cargo.Min > 0 {
+		log.Infof("  Minimum cargo: %d t", cfg.Cargo.Min)
+	}
+	if len(cfg.Bounties.Thresholds) > 0 {
+		log.Infof("  Unclaimed bounties thresholds: %v", cfg.Bounties.Thresholds)
+	}
+	if len(cfg.Readiness.Checks) > 0 {
+		log.Infof("  Readiness checks: %v", cfg.Readiness.Checks)
+	}
+	if n := len(cfg.Rules); n > 0 {
+		log.Infof("  Rules: %d", n)
+	}
+	if len(cfg.Journals) == 0 {
+		log.Infof("  Journal file path: %s", cfg.JournalPath)
+	}
+	for _, j := range cfg.Journals {
+		commander := j.Commander
+		if commander == "" {
+			commander = "from the journal"
+		}
+		log.Infof("  Journal file path: %s (CMDR %s)", j.Path, commander)
+	}
 
 	switch cfg.NotificationService {
 	case "telegram":
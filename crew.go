@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/message"
+)
+
+// Without an active crew member the fighter can't be launched, and a ship relying on its
+// fighter fights alone. The journal reports the changes of the crew roles and, when the game is
+// loaded, the wage of the active crew member (NpcCrewPaidWage with no amount) after the loadout.
+// Without those hints the crew is unknown.
+
+// crewLoginWindow is the time after the loadout of a loaded game in which the active crew
+// member is reported, the crew is inactive when none is reported in the meanwhile
+const crewLoginWindow = 10 * time.Second
+
+// crewState tracks the NPC crew of the commander
+type crewState struct {
+	known   bool   // the crew is known from the journal of the session
+	active  string // name of the active crew member, empty when none
+	alerted bool   // the missing crew has been reported
+	login   bool   // the game has been loaded and the crew is not known yet
+}
+
+// hasModule returns whether a module of the type is fitted on the ship, e.g. "fighterbay"
+// matches "int_fighterbay_size5_class1"
+func hasModule(j *journal.Loadout, item string) bool {
+	for _, m := range j.Modules {
+		if strings.Contains(strings.ToLower(m.Item), item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Notifier) crewAssign(j *journal.CrewAssign) {
+	e.crew.known = true
+	e.crew.login = false
+	if strings.EqualFold(j.Role, "Active") {
+		e.crew.active = j.Name
+	} else if e.crew.active == j.Name {
+		e.crew.active = ""
+	}
+}
+
+// crewPaid sets the crew member earning a wage or a promotion as active, as only the active
+// crew member takes a share of the earnings
+func (e *Notifier) crewPaid(name string) {
+	if name == "" {
+		return
+	}
+	e.crew.known = true
+	e.crew.login = false
+	e.crew.active = name
+}
+
+// crewLoggedIn returns whether the event ends the login of the game without a hint about the
+// crew, which is then known to be inactive
+func (e *Notifier) crewLoggedIn(ev journal.Event) bool {
+	if !e.crew.login || e.loadout == nil || ev.Time().Sub(e.loadout.Time()) < crewLoginWindow {
+		return false
+	}
+	e.crew.known = true
+	e.crew.login = false
+
+	return true
+}
+
+func (e *Notifier) crewRank(j *journal.NpcCrewRank) {
+	rank, _ := formatCombatRank(message.NewPrinter(e.lang), j.RankCombat)
+	e.crewRanks = append(e.crewRanks, fmt.Sprintf("%s (%s)", j.NpcCrewName, rank))
+}
+
+// checkCrew notifies once when no crew member is active while a fighter bay is fitted
+func (e *Notifier) checkCrew(ev journal.Event, skipNotify bool) error {
+	if e.loadout == nil || !hasModule(e.loadout, "fighterbay") || !e.crew.known || e.crew.active != "" {
+		e.crew.alerted = false
+		return nil
+	}
+	if e.crew.alerted {
+		return nil
+	}
+	e.crew.alerted = true
+
+	return e.notifyTemplate(crewInactiveTemplate, categoryCrew, ev, skipNotify)
+}
+
+func loadoutEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	e.loadout = ev.(*journal.Loadout)
+	if err := e.checkRebuy(skipNotify); err != nil {
+		return err
+	}
+
+	return e.checkCrew(ev, skipNotify)
+}
+
+func crewAssignEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.CrewAssign)
+	e.crewAssign(j)
+	printLog(j, "Crew:", j.Name, j.Role)
+
+	return e.checkCrew(j, skipNotify)
+}
+
+func crewMemberJoinsEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplate(crewJoinedTemplate, categoryCrew, ev, skipNotify)
+}
+
+func crewMemberRoleChangeEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplate(crewRoleTemplate, categoryCrew, ev, skipNotify)
+}
+
+func npcCrewRankEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.NpcCrewRank)
+	e.crewRank(j)
+	e.crewPaid(j.NpcCrewName)
+
+	return e.checkCrew(j, skipNotify)
+}
+
+func npcCrewPaidWageEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.NpcCrewPaidWage)
+	e.crewWages += j.Amount
+	e.crewPaid(j.NpcCrewName)
+
+	return e.checkCrew(j, skipNotify)
+}
+
+// crewLoginEvent checks the crew once the login of the game is over, for every event
+func crewLoginEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if !e.crewLoggedIn(ev) {
+		return nil
+	}
+
+	return e.checkCrew(ev, skipNotify)
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_crewEvents(t *testing.T) {
+	fighterBay := &journal.Loadout{
+		Ship:    "anaconda",
+		Modules: []journal.Module{{Slot: "Slot03_Size6", Item: "int_fighterbay_size6_class1", On: true}},
+	}
+	noFighterBay := &journal.Loadout{
+		Ship:    "anaconda",
+		Modules: []journal.Module{{Slot: "Slot03_Size6", Item: "int_cargorack_size6_class1", On: true}},
+	}
+	inactive := "No crew member is active while a fighter bay is fitted, the fighter can't be launched"
+
+	tests := []struct {
+		name    string
+		fn      eventFn
+		ev      journal.Event
+		wantMsg string
+	}{
+		{name: "crew unknown", fn: loadoutEvent, ev: fighterBay},
+		{name: "crew active", fn: crewAssignEvent, ev: &journal.CrewAssign{Name: "Kara Voss", CrewID: 1, Role: "Active"}},
+		{name: "other crew idle", fn: crewAssignEvent, ev: &journal.CrewAssign{Name: "Lobo", CrewID: 2, Role: "Idle"}},
+		{name: "crew idle", fn: crewAssignEvent, ev: &journal.CrewAssign{Name: "Kara Voss", CrewID: 1, Role: "Idle"}, wantMsg: inactive},
+		{name: "already reported", fn: loadoutEvent, ev: fighterBay},
+		{name: "no fighter bay", fn: loadoutEvent, ev: noFighterBay},
+		{name: "fighter bay fitted again", fn: loadoutEvent, ev: fighterBay, wantMsg: inactive},
+		{name: "multicrew", fn: crewMemberJoinsEvent, ev: &journal.CrewMemberJoins{Crew: "Jameson"}, wantMsg: "CMDR Jameson joined your crew"},
+		{name: "multicrew role", fn: crewMemberRoleChangeEvent, ev: &journal.CrewMemberRoleChange{Crew: "Jameson", Role: "FighterCon"}, wantMsg: "CMDR Jameson is now in the FighterCon role"},
+	}
+
+	n := &Notifier{cfg: &Cfg{}}
+	n.initCounters()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := tt.fn(n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func Test_crewLogin(t *testing.T) {
+	login := time.Date(2024, 3, 9, 20, 14, 2, 0, time.UTC)
+	header := func(d time.Duration) journal.Header { return journal.Header{Timestamp: login.Add(d)} }
+	fighterBay := &journal.Loadout{
+		Header:  header(0),
+		Ship:    "anaconda",
+		Modules: []journal.Module{{Slot: "Slot03_Size6", Item: "int_fighterbay_size6_class1", On: true}},
+	}
+	inactive := "No crew member is active while a fighter bay is fitted, the fighter can't be launched"
+
+	type step struct {
+		fn      eventFn
+		ev      journal.Event
+		wantMsg string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "no active crew",
+			steps: []step{
+				{fn: loadGameEvent, ev: &journal.LoadGame{Header: header(0), Commander: "Jameson"}},
+				{fn: loadoutEvent, ev: fighterBay},
+				{fn: crewLoginEvent, ev: &journal.Missions{Header: header(time.Second)}},
+				{fn: crewLoginEvent, ev: &journal.ShieldState{Header: header(time.Minute), ShieldsUp: true}, wantMsg: inactive},
+			},
+		},
+		{
+			name: "wage of the active crew member",
+			steps: []step{
+				{fn: loadGameEvent, ev: &journal.LoadGame{Header: header(0), Commander: "Jameson"}},
+				{fn: loadoutEvent, ev: fighterBay},
+				{fn: npcCrewPaidWageEvent, ev: &journal.NpcCrewPaidWage{Header: header(0), NpcCrewName: "Kara Voss"}},
+				{fn: crewLoginEvent, ev: &journal.ShieldState{Header: header(time.Minute), ShieldsUp: true}},
+			},
+		},
+		{
+			name: "without a loaded game",
+			steps: []step{
+				{fn: loadoutEvent, ev: fighterBay},
+				{fn: crewLoginEvent, ev: &journal.ShieldState{Header: header(time.Minute), ShieldsUp: true}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Notifier{cfg: &Cfg{}, credits: -1}
+			n.initCounters()
+			for _, s := range tt.steps {
+				bot := &mockBot{}
+				n.bot = bot
+
+				if err := s.fn(n, s.ev, false); err != nil {
+					t.Fatal(err)
+				}
+				if bot.sentMsg != s.wantMsg {
+					t.Fatalf("%s: wantMsg: %s, got: %s", s.ev.EventName(), s.wantMsg, bot.sentMsg)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifier_renderCrew(t *testing.T) {
+	n := &Notifier{cfg: &Cfg{}}
+	n.initCounters()
+	n.killedPirates = 3
+	n.totalPiratesReward = 120000
+
+	events := []journal.Event{
+		&journal.NpcCrewPaidWage{NpcCrewName: "Kara Voss", Amount: 4000},
+		&journal.NpcCrewRank{NpcCrewName: "Kara Voss", RankCombat: 4},
+		&journal.NpcCrewPaidWage{NpcCrewName: "Kara Voss", Amount: 8000},
+	}
+	for _, ev := range events {
+		fn := npcCrewPaidWageEvent
+		if _, ok := ev.(*journal.NpcCrewRank); ok {
+			fn = npcCrewRankEvent
+		}
+		if err := fn(n, ev, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg, err := n.render(killsTemplate, &journal.Bounty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Total rewards: 120,000 credits\nPirates killed: 3\nCrew wages: 12,000 credits\nCrew promotions: Kara Voss (Expert)"; msg != want {
+		t.Fatalf("want: %s, got: %s", want, msg)
+	}
+}
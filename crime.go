@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"sort"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// In a RES, a stray shot of the turrets or of the fighter at a security vessel makes the
+// commander wanted, and the system authority starts attacking the ship.
+
+// commitCrime adds the bounty or the fine of the crime to the faction that issued it
+func (e *Notifier) commitCrime(j *journal.CommitCrime) {
+	if j.Faction == "" {
+		return
+	}
+	if j.Bounty > 0 {
+		e.wanted[j.Faction] += j.Bounty
+	}
+	if j.Fine > 0 {
+		e.fines[j.Faction] += j.Fine
+	}
+}
+
+// payBounties clears the bounties of the faction, returning whether the commander was wanted
+// in it. Bounties without faction clear every faction.
+func (e *Notifier) payBounties(j *journal.PayBounties) bool {
+	if j.Faction == "" {
+		wanted := len(e.wanted) > 0
+		e.wanted = make(map[string]int64)
+		return wanted
+	}
+
+	wanted := false
+	for _, f := range []string{j.Faction, j.FactionLocalised} {
+		if _, ok := e.wanted[f]; ok {
+			delete(e.wanted, f)
+			wanted = true
+		}
+	}
+
+	return wanted
+}
+
+// payFines clears the fines of the faction, or all of them
+func (e *Notifier) payFines(j *journal.PayFines) {
+	if j.AllFines || j.Faction == "" {
+		e.fines = make(map[string]int64)
+		return
+	}
+
+	delete(e.fines, j.Faction)
+}
+
+// unpaidFines returns the total of the fines not paid yet
+func (e *Notifier) unpaidFines() int64 {
+	var total int64
+	for _, f := range e.fines {
+		total += f
+	}
+
+	return total
+}
+
+// wantedFactions returns the factions in which the commander is wanted, sorted by name
+func (e *Notifier) wantedFactions() []string {
+	factions := make([]string, 0, len(e.wanted))
+	for f := range e.wanted {
+		factions = append(factions, f)
+	}
+	sort.Strings(factions)
+
+	return factions
+}
+
+func commitCrimeEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.CommitCrime)
+	e.commitCrime(j)
+
+	printLog(j, "Crime committed:", j.CrimeType, "fine:", j.Fine, "bounty:", j.Bounty)
+
+	category := categoryCrime
+	if j.Bounty > 0 {
+		category = categoryCritical
+	}
+
+	return e.notifyTemplate(commitCrimeTemplate, category, j, skipNotify)
+}
+
+func crimeVictimEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplate(crimeVictimTemplate, categoryCrime, ev, skipNotify)
+}
+
+func payBountiesEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.PayBounties)
+	if !e.payBounties(j) {
+		return nil
+	}
+
+	return e.notifyTemplate(wantedClearedTemplate, categoryCrime, j, skipNotify)
+}
+
+func payFinesEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.PayFines)
+	e.payFines(j)
+
+	printLog(j, "Fines paid:", j.Amount)
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_crimeEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		fn         eventFn
+		ev         journal.Event
+		wantMsg    string
+		wantWanted []string
+		wantFines  int64
+	}{
+		{
+			name:      "fine",
+			fn:        commitCrimeEvent,
+			ev:        &journal.CommitCrime{CrimeType: "fireInNoFireZone", Faction: "Ngalinn Purple Creative Industry", Fine: 400},
+			wantMsg:   "Crime committed: fire in no fire zone, fine of 400 CR from Ngalinn Purple Creative Industry",
+			wantFines: 400,
+		},
+		{
+			name: "bounty",
+			fn:   commitCrimeEvent,
+			ev: &journal.CommitCrime{
+				CrimeType:       "assault",
+				Faction:         "Ngalinn Purple Creative Industry",
+				Victim:          "$ShipName_Police_Independent;",
+				VictimLocalised: "System Authority Vessel",
+				Bounty:          2000,
+			},
+			wantMsg:    "Crime committed: assault against System Authority Vessel, bounty of 2,000 CR, you are wanted in Ngalinn Purple Creative Industry",
+			wantWanted: []string{"Ngalinn Purple Creative Industry"},
+			wantFines:  400,
+		},
+		{
+			name:       "another faction",
+			fn:         commitCrimeEvent,
+			ev:         &journal.CommitCrime{CrimeType: "murder", Faction: "Ngalinn Crimson Boys", Bounty: 10000},
+			wantMsg:    "Crime committed: murder, bounty of 10,000 CR, you are wanted in Ngalinn Crimson Boys",
+			wantWanted: []string{"Ngalinn Crimson Boys", "Ngalinn Purple Creative Industry"},
+			wantFines:  400,
+		},
+		{
+			name:       "victim",
+			fn:         crimeVictimEvent,
+			ev:         &journal.CrimeVictim{Offender: "Lobo", CrimeType: "assault", Bounty: 500},
+			wantMsg:    "Lobo committed assault against you, bounty of 500 CR",
+			wantWanted: []string{"Ngalinn Crimson Boys", "Ngalinn Purple Creative Industry"},
+			wantFines:  400,
+		},
+		{
+			name:       "bounty paid",
+			fn:         payBountiesEvent,
+			ev:         &journal.PayBounties{Faction: "Ngalinn Crimson Boys", Amount: 10000},
+			wantMsg:    "You are no longer wanted in Ngalinn Crimson Boys",
+			wantWanted: []string{"Ngalinn Purple Creative Industry"},
+			wantFines:  400,
+		},
+		{
+			name:       "fine of another faction",
+			fn:         commitCrimeEvent,
+			ev:         &journal.CommitCrime{CrimeType: "recklessWeaponsDischarge", Faction: "Ngalinn Crimson Boys", Fine: 250},
+			wantMsg:    "Crime committed: reckless weapons discharge, fine of 250 CR from Ngalinn Crimson Boys",
+			wantWanted: []string{"Ngalinn Purple Creative Industry"},
+			wantFines:  650,
+		},
+		{
+			name:       "fine paid",
+			fn:         payFinesEvent,
+			ev:         &journal.PayFines{Faction: "Ngalinn Purple Creative Industry", Amount: 400},
+			wantWanted: []string{"Ngalinn Purple Creative Industry"},
+			wantFines:  250,
+		},
+		{
+			name:       "all fines paid",
+			fn:         payFinesEvent,
+			ev:         &journal.PayFines{AllFines: true, Amount: 250},
+			wantWanted: []string{"Ngalinn Purple Creative Industry"},
+		},
+		{
+			name:       "bounty of another faction paid",
+			fn:         payBountiesEvent,
+			ev:         &journal.PayBounties{Faction: "Ngalinn Crimson Boys", Amount: 10000},
+			wantWanted: []string{"Ngalinn Purple Creative Industry"},
+		},
+	}
+
+	n := &Notifier{cfg: &Cfg{}}
+	n.initCounters()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := tt.fn(n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+
+			stats := n.sessionStats()
+			if stats.Fines != tt.wantFines {
+				t.Fatalf("wantFines: %d, got: %d", tt.wantFines, stats.Fines)
+			}
+
+			got := stats.Wanted
+			if len(got) != len(tt.wantWanted) {
+				t.Fatalf("wantWanted: %v, got: %v", tt.wantWanted, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantWanted[i] {
+					t.Fatalf("wantWanted: %v, got: %v", tt.wantWanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifier_renderWanted(t *testing.T) {
+	n := &Notifier{cfg: &Cfg{}}
+	n.initCounters()
+	n.killedPirates = 3
+	n.totalPiratesReward = 120000
+	n.wanted["Ngalinn Crimson Boys"] = 10000
+	n.fines["Ngalinn Crimson Boys"] = 400
+	n.fines["Ngalinn Purple Creative Industry"] = 1200
+
+	msg, err := n.render(killsTemplate, &journal.Bounty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Total rewards: 120,000 credits\nPirates killed: 3\nWanted in: Ngalinn Crimson Boys\nUnpaid fines: 1,600 credits"; msg != want {
+		t.Fatalf("want: %s, got: %s", want, msg)
+	}
+}
+
+func TestNotifier_initNotifierFines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Journal.2024-03-09T201233.01.log"),
+		`{"timestamp":"2024-03-09T20:12:33Z","event":"Fileheader","part":1}`+"\n"+
+			`{"timestamp":"2024-03-09T20:30:00Z","event":"CommitCrime","CrimeType":"fireInNoFireZone","Faction":"Ngalinn Crimson Boys","Fine":400}`+"\n"+
+			`{"timestamp":"2024-03-09T20:31:00Z","event":"CommitCrime","CrimeType":"fireInNoFireZone","Faction":"Pilots' Federation","Fine":250}`+"\n"+
+			`{"timestamp":"2024-03-09T21:00:00Z","event":"PayFines","Amount":400,"AllFines":false,"Faction":"Ngalinn Crimson Boys"}`+"\n",
+		time.Now())
+
+	n := &Notifier{cfg: &Cfg{}, journalPath: dir, position: journal.Position{File: "Journal.2024-03-09T201233.01.log"}}
+	n.initNotifier()
+
+	if got := n.unpaidFines(); got != 250 {
+		t.Fatalf("want: 250, got: %d", got)
+	}
+}
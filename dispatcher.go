@@ -0,0 +1,282 @@
+package notifier
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/bots"
+)
+
+// Default settings of the dispatcher
+const (
+	defaultQueueSize    = 100
+	defaultSendTimeout  = 30 * time.Second
+	defaultQueueWorkers = 1
+
+	// How long a critical notification waits for room in a full queue
+	criticalEnqueueWait = 2 * time.Second
+)
+
+// dispatcherMetrics are published by expvar at /debug/vars (see the -stats flag), keyed by
+// "<backend>.<metric>", e.g. "telegram.depth"
+var dispatcherMetrics = expvar.NewMap("dispatcher")
+
+// dispatcher delivers the notifications in the background, with a bounded queue and a pool
+// of workers per backend, so that slow backends never stall the processing of the journal
+type dispatcher struct {
+	queues map[string]*backendQueue
+	outbox *outbox                   // stores failed notifications for retry, can be nil
+	late   func(notification) string // returns the text of notifications delivered late
+}
+
+type backendQueue struct {
+	name     string
+	bot      bots.Bot
+	timeout  time.Duration
+	outbox   *outbox
+	limiter  *tokenBucket
+	coalesce time.Duration     // notifications queued within this window are merged
+	critical chan notification // critical notifications jump the queue
+	normal   chan notification
+}
+
+// newDispatcher starts the workers of each backend and, when the outbox is not nil, the
+// retry of the notifications that couldn't be delivered
+func newDispatcher(cfg *Cfg, backends map[string]bots.Bot, o *outbox, late func(notification) string) *dispatcher {
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	timeout := cfg.SendTimeout
+	if timeout <= 0 {
+		timeout = defaultSendTimeout
+	}
+	workers := cfg.QueueWorkers
+	if workers <= 0 {
+		workers = defaultQueueWorkers
+	}
+
+	d := &dispatcher{
+		queues: make(map[string]*backendQueue),
+		outbox: o,
+		late:   late,
+	}
+	for name, bot := range backends {
+		rate, burst := cfg.rateLimit(name)
+		q := &backendQueue{
+			name:     name,
+			bot:      bot,
+			timeout:  timeout,
+			outbox:   o,
+			limiter:  newTokenBucket(rate, burst, time.Now()),
+			coalesce: cfg.Coalesce,
+			critical: make(chan notification, size),
+			normal:   make(chan notification, size),
+		}
+		d.queues[name] = q
+
+		dispatcherMetrics.Set(name+".depth", expvar.Func(func() interface{} {
+			return len(q.critical) + len(q.normal)
+		}))
+
+		for i := 0; i < workers; i++ {
+			go q.work()
+		}
+	}
+
+	if o != nil {
+		go d.retryOutbox()
+	}
+
+	return d
+}
+
+func (d *dispatcher) retryOutbox() {
+	for now := range time.Tick(time.Second) {
+		d.outbox.retry(now, func(backend string, n notification) error {
+			q, ok := d.queues[backend]
+			if !ok {
+				log.Infof("Dropping notification for %s, which is not configured anymore: %s", backend, n.Text)
+				return nil
+			}
+
+			if d.late != nil && isLate(n, now) {
+				n.Text = d.late(n)
+			}
+
+			return q.send(n)
+		})
+	}
+}
+
+// enqueue adds the notification to the queue of its backend or, when it has none, to the
+// queue of every backend, returning an error when a queue is full
+func (d *dispatcher) enqueue(n notification) error {
+	if n.Backend != "" {
+		q, ok := d.queues[n.Backend]
+		if !ok {
+			return fmt.Errorf("unknown backend %s, notification dropped: %s", n.Backend, n.Text)
+		}
+		return q.enqueue(n)
+	}
+
+	var err error
+	for _, q := range d.queues {
+		if qErr := q.enqueue(n); qErr != nil {
+			err = qErr
+		}
+	}
+
+	return err
+}
+
+// enqueue adds the notification to the queue. When the queue is full, critical notifications
+// wait briefly for room, then the notification is stored in the outbox for a later delivery
+// or, without outbox, dropped.
+func (q *backendQueue) enqueue(n notification) error {
+	queue := q.normal
+	if n.Category == categoryCritical {
+		queue = q.critical
+	}
+
+	select {
+	case queue <- n:
+		dispatcherMetrics.Add(q.name+".queued", 1)
+		return nil
+	default:
+	}
+
+	if n.Category == categoryCritical {
+		timer := time.NewTimer(criticalEnqueueWait)
+		defer timer.Stop()
+
+		select {
+		case queue <- n:
+			dispatcherMetrics.Add(q.name+".queued", 1)
+			return nil
+		case <-timer.C:
+		}
+	}
+
+	if q.outbox != nil {
+		dispatcherMetrics.Add(q.name+".overflow", 1)
+		log.Infof("%s queue is full, notification moved to the outbox: %s", q.name, n.Text)
+		q.outbox.add(q.name, n, time.Now())
+		return nil
+	}
+
+	dispatcherMetrics.Add(q.name+".dropped", 1)
+	return fmt.Errorf("%s queue is full, notification dropped: %s", q.name, n.Text)
+}
+
+func (q *backendQueue) work() {
+	for {
+		var n notification
+
+		// Always deliver pending critical notifications first
+		select {
+		case n = <-q.critical:
+		default:
+			select {
+			case n = <-q.critical:
+			case n = <-q.normal:
+			}
+		}
+
+		if n.Category != categoryCritical && q.coalesce > 0 {
+			n = q.collect(n)
+		}
+
+		q.deliver(n)
+	}
+}
+
+// collect waits for the coalescing window, merging the notifications queued in the
+// meanwhile with the first one. Critical notifications are delivered immediately.
+func (q *backendQueue) collect(first notification) notification {
+	batch := []notification{first}
+
+	timer := time.NewTimer(q.coalesce)
+	defer timer.Stop()
+
+	for {
+		select {
+		case n := <-q.critical:
+			q.deliver(n)
+		case n := <-q.normal:
+			batch = append(batch, n)
+		case <-timer.C:
+			if len(batch) > 1 {
+				dispatcherMetrics.Add(q.name+".coalesced", int64(len(batch)-1))
+			}
+			return coalesce(batch)
+		}
+	}
+}
+
+func (q *backendQueue) deliver(n notification) {
+	if err := q.send(n); err != nil {
+		dispatcherMetrics.Add(q.name+".failed", 1)
+		log.Infof("[ERROR] error sending message with %s: %v", q.name, err)
+
+		// Messages rejected by the backend would be rejected again
+		var permErr *bots.PermanentError
+		if q.outbox != nil && !errors.As(err, &permErr) {
+			id := q.outbox.add(q.name, n, time.Now())
+			q.outbox.removeWhenDelivered(id, err)
+		}
+		return
+	}
+
+	dispatcherMetrics.Add(q.name+".sent", 1)
+}
+
+// send waits for the rate limiter and sends the notification. When the backend asks to
+// retry later, the sends are paused accordingly and the notification is sent again once.
+func (q *backendQueue) send(n notification) error {
+	q.limiter.wait()
+	err := q.sendWithTimeout(n)
+
+	var rateErr *bots.RateLimitError
+	if !errors.As(err, &rateErr) {
+		return err
+	}
+
+	dispatcherMetrics.Add(q.name+".rate_limited", 1)
+	log.Infof("%s is rate limiting, retrying in %s", q.name, rateErr.RetryAfter)
+	q.limiter.pause(time.Now().Add(rateErr.RetryAfter))
+	q.limiter.wait()
+
+	return q.sendWithTimeout(n)
+}
+
+// sendTimeoutError is returned when a send is abandoned after the queue timeout. The send
+// goes on in the background and its result is reported on the channel.
+type sendTimeoutError struct {
+	timeout time.Duration
+	result  <-chan error
+}
+
+func (e *sendTimeoutError) Error() string {
+	return fmt.Sprintf("timeout after %s", e.timeout)
+}
+
+// sendWithTimeout sends the notification, giving up after the queue timeout. The bots also
+// have timeouts on their HTTP clients, so that abandoned requests don't pile up.
+func (q *backendQueue) sendWithTimeout(n notification) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- bots.SendMessage(q.bot, bots.Message{Text: n.Text, Silent: n.Silent})
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(q.timeout):
+		dispatcherMetrics.Add(q.name+".timeouts", 1)
+		return &sendTimeoutError{timeout: q.timeout, result: done}
+	}
+}
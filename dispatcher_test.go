@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/bots"
+)
+
+// chanBot sends the messages to a channel, waiting for release before returning
+type chanBot struct {
+	sent    chan string
+	release chan struct{}
+}
+
+func (b *chanBot) Start() {}
+func (b *chanBot) Send(msg string) error {
+	<-b.release
+	b.sent <- msg
+	return nil
+}
+
+func Test_dispatcherCriticalFirst(t *testing.T) {
+	bot := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+	d := newDispatcher(&Cfg{}, map[string]bots.Bot{"test-critical": bot}, nil, nil)
+
+	// The first notification keeps the worker busy while the others are queued
+	mustEnqueue(t, d, notification{Category: categoryShields, Text: "first"})
+	time.Sleep(10 * time.Millisecond)
+	mustEnqueue(t, d, notification{Category: categoryShields, Text: "normal"})
+	mustEnqueue(t, d, notification{Category: categoryCritical, Text: "critical"})
+
+	close(bot.release)
+
+	for _, want := range []string{"first", "critical", "normal"} {
+		select {
+		case got := <-bot.sent:
+			if got != want {
+				t.Fatalf("want: %s, got: %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %s", want)
+		}
+	}
+}
+
+func Test_dispatcherQueueFull(t *testing.T) {
+	bot := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+	defer close(bot.release)
+	d := newDispatcher(&Cfg{QueueSize: 1, SendTimeout: 50 * time.Millisecond}, map[string]bots.Bot{"test-full": bot}, nil, nil)
+
+	mustEnqueue(t, d, notification{Text: "in flight"})
+	time.Sleep(10 * time.Millisecond)
+	mustEnqueue(t, d, notification{Text: "queued"})
+
+	if err := d.enqueue(notification{Text: "dropped"}); err == nil {
+		t.Fatalf("expected error with a full queue")
+	}
+
+	// The blocked send times out, so that the worker moves to the next notification
+	time.Sleep(100 * time.Millisecond)
+	mustEnqueue(t, d, notification{Text: "after timeout"})
+}
+
+func Test_dispatcherBackend(t *testing.T) {
+	first := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+	second := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+	close(first.release)
+	close(second.release)
+	d := newDispatcher(&Cfg{}, map[string]bots.Bot{"test-first": first, "test-first:2": second}, nil, nil)
+
+	mustEnqueue(t, d, notification{Backend: "test-first:2", Text: "routed"})
+	mustEnqueue(t, d, notification{Text: "everywhere"})
+	if err := d.enqueue(notification{Backend: "unknown", Text: "dropped"}); err == nil {
+		t.Fatalf("expected error with an unknown backend")
+	}
+
+	for bot, want := range map[*chanBot][]string{first: {"everywhere"}, second: {"routed", "everywhere"}} {
+		for _, w := range want {
+			select {
+			case got := <-bot.sent:
+				if got != w {
+					t.Fatalf("want: %s, got: %s", w, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout waiting for %s", w)
+			}
+		}
+	}
+	if len(first.sent) != 0 {
+		t.Fatalf("want: no more messages, got: %s", <-first.sent)
+	}
+}
+
+func mustEnqueue(t *testing.T, d *dispatcher, n notification) {
+	t.Helper()
+
+	if err := d.enqueue(n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_dispatcherQueueOverflow(t *testing.T) {
+	o, err := newOutbox(OutboxCfg{Path: filepath.Join(t.TempDir(), "outbox.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+	defer close(bot.release)
+	d := newDispatcher(&Cfg{QueueSize: 1}, map[string]bots.Bot{"test-overflow": bot}, o, nil)
+
+	mustEnqueue(t, d, notification{Text: "in flight"})
+	time.Sleep(10 * time.Millisecond)
+	mustEnqueue(t, d, notification{Text: "queued"})
+	mustEnqueue(t, d, notification{Text: "overflow"})
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.entries) != 1 || o.entries[0].Notification.Text != "overflow" {
+		t.Fatalf("want the overflow in the outbox, got: %v", o.entries)
+	}
+}
+
+func Test_dispatcherCriticalWaits(t *testing.T) {
+	bot := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+	d := newDispatcher(&Cfg{QueueSize: 1}, map[string]bots.Bot{"test-critical-wait": bot}, nil, nil)
+
+	mustEnqueue(t, d, notification{Category: categoryCritical, Text: "in flight"})
+	time.Sleep(10 * time.Millisecond)
+	mustEnqueue(t, d, notification{Category: categoryCritical, Text: "queued"})
+
+	// The worker makes room while the third critical notification waits
+	time.AfterFunc(50*time.Millisecond, func() { close(bot.release) })
+	mustEnqueue(t, d, notification{Category: categoryCritical, Text: "waiting"})
+
+	for _, want := range []string{"in flight", "queued", "waiting"} {
+		select {
+		case got := <-bot.sent:
+			if got != want {
+				t.Fatalf("want: %s, got: %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %s", want)
+		}
+	}
+}
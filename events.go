@@ -1,136 +1,165 @@
 package notifier
 
 import (
-	"fmt"
-	"math"
-
 	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
-type eventFn func(*Notifier, journalEvent, bool) error
+// eventFn handles a journal event, the decoded struct is the one registered for the event
+// name in the journal package
+type eventFn func(*Notifier, journal.Event, bool) error
 
-func (e *Notifier) notify(msg string, skipNotify bool) error {
-	if skipNotify {
+func hullDamageEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.HullDamage)
+	if j.Fighter && !e.cfg.FighterNotifs {
 		return nil
 	}
 
-	if err := e.bot.Send(msg); err != nil {
-		return fmt.Errorf("error sending message: %v", err)
+	category := categoryHull
+	if !j.Fighter && j.Health <= e.cfg.CriticalHull {
+		category = categoryCritical
 	}
 
-	return nil
+	return e.notifyTemplate(hullDamageTemplate, category, j, skipNotify)
 }
 
-func hullDamageEvent(e *Notifier, j journalEvent, skipNotify bool) error {
-	if j.Fighter && !e.cfg.FighterNotifs {
-		return nil
+// diedEvent reports the rebuy of the ship and the bounties lost with it
+func diedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	values := map[string]interface{}{"Rebuy": int64(0), "Unclaimed": e.unclaimedTotal()}
+	if e.loadout != nil {
+		values["Rebuy"] = e.loadout.Rebuy
 	}
+	e.loseBounties()
 
-	prefix := "Ship"
-	if j.Fighter {
-		prefix = "Fighter"
-	}
-
-	h := int(math.Round(j.Health * 100))
-	return e.notify(fmt.Sprintf("%s hull damage detected, integrity is %d%%", prefix, h), skipNotify)
-
+	return e.notifyTemplateWith(diedTemplate, categoryCritical, ev, values, skipNotify)
 }
 
-func diedEvent(e *Notifier, j journalEvent, skipNotify bool) error {
-	return e.notify("Your ship has been destroyed", skipNotify)
-}
-
-func shieldStateEvent(e *Notifier, j journalEvent, skipNotify bool) error {
-	var msg string
+func shieldStateEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.ShieldState)
 	if j.ShieldsUp {
-		msg = "Shields are up again"
-	} else {
-		msg = "Shields are down!"
+		return e.notifyTemplate(shieldsUpTemplate, categoryShields, j, skipNotify)
 	}
 
-	return e.notify(msg, skipNotify)
+	return e.notifyTemplate(shieldsDownTemplate, categoryShields, j, skipNotify)
 }
 
-func bountyEvent(e *Notifier, j journalEvent, skipNotify bool) error {
-	e.totalPiratesReward += j.TotalPiratesReward
+func bountyEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.Bounty)
+	e.totalPiratesReward += j.TotalReward
+	e.addBounty(j)
 	e.killedPirates++
 
-	j.printLog("Pirates killed:", e.killedPirates)
+	printLog(j, "Pirates killed:", e.killedPirates)
+
+	bounties, _ := formatCredits(message.NewPrinter(language.English), e.totalPiratesReward)
+	printLog(j, "Total bounty rewards:", bounties)
 
-	p := message.NewPrinter(language.Make("en"))
-	bounties := p.Sprintf("%d", e.totalPiratesReward)
-	j.printLog("Total bounty rewards:", bounties)
+	if err := e.checkUnclaimed(j, skipNotify); err != nil {
+		return err
+	}
 
 	if !e.cfg.KillsNotifs {
 		return nil
 	}
 
 	if !e.cfg.KillsSilentNotifs || e.killedPirates%10 == 0 {
-		return e.notify(fmt.Sprintf("Total rewards: %s credits\nPirates killed: %d", bounties, e.killedPirates), skipNotify)
+		return e.notifyTemplate(killsTemplate, categoryKills, j, skipNotify)
 	}
 
 	return nil
 }
 
-func missionAcceptedEvent(e *Notifier, j journalEvent, skipNotify bool) error {
+func missionAcceptedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.MissionAccepted)
 	e.activeMissions++
 	e.loggedMissions[j.MissionID] = false
+	e.massacres[j.MissionID] = isMassacre(j.Name)
 
-	j.printLog("Active missions:", e.activeMissions)
+	printLog(j, "Active missions:", e.activeMissions)
 
 	return nil
 }
 
-func missionRedirectedEvent(e *Notifier, j journalEvent, skipNotify bool) error {
+func missionRedirectedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.MissionRedirected)
 	if e.loggedMissions[j.MissionID] {
 		return nil
 	}
 
 	e.activeMissions--
 	e.loggedMissions[j.MissionID] = true
+	delete(e.massacres, j.MissionID)
 
-	j.printLog("Active missions:", e.activeMissions)
+	printLog(j, "Active missions:", e.activeMissions)
 
 	if e.activeMissions == 0 {
-		return e.notify("No more active missions, go collect new ones!", skipNotify)
+		return e.notifyTemplate(missionsCompletedTemplate, categoryMissions, j, skipNotify)
 	}
 
 	return nil
 }
 
-func missionCompletedEvent(e *Notifier, j journalEvent, skipNotify bool) error {
+func missionCompletedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.MissionCompleted)
 	if e.loggedMissions[j.MissionID] {
 		return nil
 	}
 
 	e.activeMissions--
 	delete(e.loggedMissions, j.MissionID)
+	delete(e.massacres, j.MissionID)
 
-	e.totalMissionsReward += j.MissionReward
-	j.printLog("Obtained reward for missions until now:", e.totalMissionsReward)
+	e.totalMissionsReward += j.Reward
+	printLog(j, "Obtained reward for missions until now:", e.totalMissionsReward)
 
-	j.printLog("Active missions:", e.activeMissions)
+	printLog(j, "Active missions:", e.activeMissions)
 
 	if e.activeMissions == 0 {
-		return e.notify("No more active missions, go collect new ones!", skipNotify)
+		return e.notifyTemplate(missionsCompletedTemplate, categoryMissions, j, skipNotify)
 	}
 
 	return nil
 }
 
-func missionAbandonedEvent(e *Notifier, j journalEvent, skipNotify bool) error {
+func missionAbandonedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.MissionAbandoned)
 	e.activeMissions--
 	delete(e.loggedMissions, j.MissionID)
+	delete(e.massacres, j.MissionID)
 
 	return nil
 }
 
-func missionsInitEvent(e *Notifier, j journalEvent, skipNotify bool) error {
+func missionsInitEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
 	log.Infoln("Found missions log message, running new initialization")
 	e.initNotifier()
 
 	return nil
 }
+
+func interdictedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.Interdicted)
+	category := categoryInterdiction
+	if j.IsPlayer {
+		category = categoryCritical
+	}
+
+	return e.notifyTemplate(interdictedTemplate, category, j, skipNotify)
+}
+
+func escapeInterdictionEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.EscapeInterdiction)
+	category := categoryInterdiction
+	if j.IsPlayer {
+		category = categoryCritical
+	}
+
+	return e.notifyTemplate(escapeInterdictionTemplate, category, j, skipNotify)
+}
+
+func interdictionEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplate(interdictionTemplate, categoryInterdiction, ev, skipNotify)
+}
@@ -3,6 +3,8 @@ package notifier
 import (
 	"fmt"
 	"testing"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
 )
 
 type mockBot struct {
@@ -20,7 +22,7 @@ func Test_hullDamageEvent(t *testing.T) {
 	tests := []struct {
 		name    string
 		n       *Notifier
-		j       journalEvent
+		j       journal.Event
 		wantMsg string
 		sendErr error
 	}{
@@ -31,7 +33,7 @@ func Test_hullDamageEvent(t *testing.T) {
 					FighterNotifs: false,
 				},
 			},
-			j: journalEvent{
+			j: &journal.HullDamage{
 				Fighter: true,
 			},
 		},
@@ -40,7 +42,7 @@ func Test_hullDamageEvent(t *testing.T) {
 			n: &Notifier{
 				cfg: &Cfg{},
 			},
-			j: journalEvent{
+			j: &journal.HullDamage{
 				Fighter: false,
 				Health:  0.399871,
 			},
@@ -53,7 +55,7 @@ func Test_hullDamageEvent(t *testing.T) {
 					FighterNotifs: true,
 				},
 			},
-			j: journalEvent{
+			j: &journal.HullDamage{
 				Fighter: true,
 				Health:  0.413871,
 			},
@@ -64,7 +66,7 @@ func Test_hullDamageEvent(t *testing.T) {
 			n: &Notifier{
 				cfg: &Cfg{},
 			},
-			j: journalEvent{
+			j: &journal.HullDamage{
 				Health: 0.413871,
 			},
 			sendErr: fmt.Errorf("fake"),
@@ -103,7 +105,7 @@ func Test_diedEvent(t *testing.T) {
 	tests := []struct {
 		name    string
 		n       *Notifier
-		j       journalEvent
+		j       journal.Event
 		wantMsg string
 		sendErr error
 	}{
@@ -112,7 +114,7 @@ func Test_diedEvent(t *testing.T) {
 			n: &Notifier{
 				cfg: &Cfg{},
 			},
-			j:       journalEvent{},
+			j:       &journal.Died{},
 			wantMsg: "Your ship has been destroyed",
 		},
 		{
@@ -120,7 +122,7 @@ func Test_diedEvent(t *testing.T) {
 			n: &Notifier{
 				cfg: &Cfg{},
 			},
-			j:       journalEvent{},
+			j:       &journal.Died{},
 			sendErr: fmt.Errorf("fake"),
 		},
 	}
@@ -152,3 +154,65 @@ func Test_diedEvent(t *testing.T) {
 		})
 	}
 }
+
+func Test_interdictionEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      eventFn
+		j       journal.Event
+		wantMsg string
+	}{
+		{
+			name: "interdicted by a player",
+			fn:   interdictedEvent,
+			j: &journal.Interdicted{
+				Interdictor: "Lobo",
+				IsPlayer:    true,
+				CombatRank:  7,
+				Faction:     "Ngalinn Crimson Boys",
+			},
+			wantMsg: "Interdicted by CMDR Lobo (Deadly, Ngalinn Crimson Boys), you couldn't escape",
+		},
+		{
+			name: "submitted to a NPC",
+			fn:   interdictedEvent,
+			j: &journal.Interdicted{
+				Submitted:            true,
+				Interdictor:          "$npc_name_decorate:#name=Kara Voss;",
+				InterdictorLocalised: "Kara Voss",
+				CombatRank:           4,
+			},
+			wantMsg: "Interdicted by Kara Voss (Expert), you submitted",
+		},
+		{
+			name:    "escaped",
+			fn:      escapeInterdictionEvent,
+			j:       &journal.EscapeInterdiction{Interdictor: "Kara Voss"},
+			wantMsg: "Escaped the interdiction of Kara Voss",
+		},
+		{
+			name: "interdiction failed",
+			fn:   interdictionEvent,
+			j: &journal.Interdiction{
+				Interdicted: "Lee Kim",
+				CombatRank:  5,
+				Faction:     "Ngalinn Crimson Boys",
+			},
+			wantMsg: "Interdiction failed: Lee Kim (Master, Ngalinn Crimson Boys)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n := &Notifier{cfg: &Cfg{}, bot: bot}
+
+			if err := tt.fn(n, tt.j, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
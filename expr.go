@@ -0,0 +1,578 @@
+package notifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expression is a compiled rule condition, evaluated against the fields of a journal event.
+//
+// The syntax supports:
+//   - literals: numbers, "strings" or 'strings', true, false and null
+//   - identifiers: event fields, with dots for nested objects (e.g. Session.Kills)
+//   - operators: || && ! == != < <= > >= + - * / and parentheses
+//   - functions: contains(s, sub), lower(s), len(v)
+//
+// Missing fields evaluate to null.
+type expression func(env map[string]interface{}) (interface{}, error)
+
+// compileExpression parses the expression, returning an error on invalid syntax
+func compileExpression(src string) (expression, error) {
+	tokens, err := lexExpression(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+
+	return e, nil
+}
+
+// evalBool evaluates the expression, converting the result to a boolean
+func (e expression) evalBool(env map[string]interface{}) (bool, error) {
+	v, err := e(env)
+	if err != nil {
+		return false, err
+	}
+
+	return truthy(v), nil
+}
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")", ","}
+
+func lexExpression(src string) ([]exprToken, error) {
+	var tokens []exprToken
+
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(src) && rune(src[j]) != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, text: b.String(), pos: i})
+			i = j + 1
+
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: src[i:j], pos: i})
+			i = j
+
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_' || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: tokenIdent, text: src[i:j], pos: i})
+			i = j
+
+		default:
+			found := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, exprToken{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator {
+		return "", false
+	}
+
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *exprParser) expect(op string) error {
+	if _, ok := p.peek(op); !ok {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("expected %q at the end of the expression", op)
+		}
+		return fmt.Errorf("expected %q at position %d", op, p.tokens[p.pos].pos)
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *exprParser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.peek("||"); !ok {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(env map[string]interface{}) (interface{}, error) {
+			lv, err := l.evalBool(env)
+			if err != nil || lv {
+				return lv, err
+			}
+			return right.evalBool(env)
+		}
+	}
+}
+
+func (p *exprParser) parseAnd() (expression, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.peek("&&"); !ok {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(env map[string]interface{}) (interface{}, error) {
+			lv, err := l.evalBool(env)
+			if err != nil || !lv {
+				return false, err
+			}
+			return right.evalBool(env)
+		}
+	}
+}
+
+func (p *exprParser) parseComparison() (expression, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.peek("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return left, nil
+	}
+	p.pos++
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(env map[string]interface{}) (interface{}, error) {
+		lv, err := left(env)
+		if err != nil {
+			return nil, err
+		}
+		rv, err := right(env)
+		if err != nil {
+			return nil, err
+		}
+
+		return compareValues(op, lv, rv)
+	}, nil
+}
+
+func (p *exprParser) parseAdditive() (expression, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.peek("+", "-")
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(op, left, right)
+	}
+}
+
+func (p *exprParser) parseMultiplicative() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.peek("*", "/")
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(op, left, right)
+	}
+}
+
+func (p *exprParser) parseUnary() (expression, error) {
+	op, ok := p.peek("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+	p.pos++
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "!" {
+		return func(env map[string]interface{}) (interface{}, error) {
+			v, err := operand.evalBool(env)
+			return !v, err
+		}, nil
+	}
+
+	return func(env map[string]interface{}) (interface{}, error) {
+		v, err := operand(env)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %v", v)
+		}
+		return -f, nil
+	}, nil
+}
+
+func (p *exprParser) parsePrimary() (expression, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of the expression")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return constant(f), nil
+
+	case tokenString:
+		return constant(t.text), nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return constant(true), nil
+		case "false":
+			return constant(false), nil
+		case "null", "nil":
+			return constant(nil), nil
+		}
+
+		if _, ok := p.peek("("); ok {
+			return p.parseCall(t)
+		}
+
+		path := strings.Split(t.text, ".")
+		return func(env map[string]interface{}) (interface{}, error) {
+			return lookupField(env, path), nil
+		}, nil
+
+	default:
+		if t.text == "(" {
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+}
+
+func (p *exprParser) parseCall(name exprToken) (expression, error) {
+	fn, ok := exprFuncs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+
+	// Skip the open parenthesis
+	p.pos++
+
+	var args []expression
+	if _, ok := p.peek(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if _, ok := p.peek(","); !ok {
+				break
+			}
+			p.pos++
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) != fn.args {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", name.text, fn.args, len(args))
+	}
+
+	return func(env map[string]interface{}) (interface{}, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			v, err := arg(env)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return fn.call(values)
+	}, nil
+}
+
+type exprFunc struct {
+	args int
+	call func([]interface{}) (interface{}, error)
+}
+
+var exprFuncs = map[string]exprFunc{
+	"contains": {args: 2, call: func(v []interface{}) (interface{}, error) {
+		s, ok1 := v[0].(string)
+		sub, ok2 := v[1].(string)
+		return ok1 && ok2 && strings.Contains(s, sub), nil
+	}},
+	"lower": {args: 1, call: func(v []interface{}) (interface{}, error) {
+		s, _ := v[0].(string)
+		return strings.ToLower(s), nil
+	}},
+	"len": {args: 1, call: func(v []interface{}) (interface{}, error) {
+		switch x := v[0].(type) {
+		case string:
+			return float64(len(x)), nil
+		case []interface{}:
+			return float64(len(x)), nil
+		case map[string]interface{}:
+			return float64(len(x)), nil
+		default:
+			return float64(0), nil
+		}
+	}},
+}
+
+func constant(v interface{}) expression {
+	return func(map[string]interface{}) (interface{}, error) {
+		return v, nil
+	}
+}
+
+// lookupField walks the nested objects of the environment, returning nil for missing fields
+func lookupField(env map[string]interface{}, path []string) interface{} {
+	var v interface{} = env
+	for _, key := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+
+	return v
+}
+
+func arithmetic(op string, left, right expression) expression {
+	return func(env map[string]interface{}) (interface{}, error) {
+		lv, err := left(env)
+		if err != nil {
+			return nil, err
+		}
+		rv, err := right(env)
+		if err != nil {
+			return nil, err
+		}
+
+		if op == "+" {
+			if ls, ok := lv.(string); ok {
+				return ls + fmt.Sprint(rv), nil
+			}
+		}
+
+		l, lok := lv.(float64)
+		r, rok := rv.(float64)
+		if !lok || !rok {
+			return nil, fmt.Errorf("cannot apply %s to %v and %v", op, lv, rv)
+		}
+
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		default:
+			if r == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return l / r, nil
+		}
+	}
+}
+
+func compareValues(op string, l, r interface{}) (interface{}, error) {
+	switch op {
+	case "==", "!=":
+		// Objects and arrays can't be compared, == on them would panic
+		if !scalar(l) || !scalar(r) {
+			return nil, fmt.Errorf("cannot compare %v and %v", l, r)
+		}
+	}
+
+	switch op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	}
+
+	// Missing fields never match an ordering
+	if l == nil || r == nil {
+		return false, nil
+	}
+
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %v and %v", l, r)
+		}
+		return compareOrdered(op, lv < rv, lv > rv), nil
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %v and %v", l, r)
+		}
+		return compareOrdered(op, lv < rv, lv > rv), nil
+	default:
+		return nil, fmt.Errorf("cannot compare %v and %v", l, r)
+	}
+}
+
+// scalar returns whether v is a null, boolean, number or string value
+func scalar(v interface{}) bool {
+	switch v.(type) {
+	case nil, bool, float64, string:
+		return true
+	default:
+		return false
+	}
+}
+
+func compareOrdered(op string, less, greater bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return !greater
+	case ">":
+		return greater
+	default:
+		return !less
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return x != ""
+	default:
+		return true
+	}
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"testing"
+)
+
+func Test_compileExpression(t *testing.T) {
+	env := map[string]interface{}{
+		"event":        "ShipTargeted",
+		"PilotRank":    "Elite",
+		"TargetLocked": true,
+		"ScanStage":    float64(3),
+		"Ship":         "python",
+		"Session": map[string]interface{}{
+			"Kills": float64(12),
+		},
+		"Items": []interface{}{"a", "b"},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "string equality", expr: `event == "ShipTargeted" && PilotRank == 'Elite'`, want: true},
+		{name: "string inequality", expr: `PilotRank != "Elite"`, want: false},
+		{name: "number comparison", expr: `ScanStage >= 3`, want: true},
+		{name: "nested field", expr: `Session.Kills > 10`, want: true},
+		{name: "arithmetic", expr: `Session.Kills / 4 == ScanStage`, want: true},
+		{name: "negative number", expr: `-ScanStage < 0`, want: true},
+		{name: "boolean field", expr: `TargetLocked`, want: true},
+		{name: "not", expr: `!TargetLocked || Ship == "python"`, want: true},
+		{name: "parentheses", expr: `(PilotRank == "Deadly" || PilotRank == "Elite") && ScanStage == 3`, want: true},
+		{name: "missing field is null", expr: `Missing == null`, want: true},
+		{name: "missing field never matches an ordering", expr: `Missing > 3`, want: false},
+		{name: "missing nested field", expr: `Session.Missing.Deep == null`, want: true},
+		{name: "contains", expr: `contains(lower(PilotRank), "eli")`, want: true},
+		{name: "len", expr: `len(Items) == 2`, want: true},
+		{name: "short circuit", expr: `false && (ScanStage > "x")`, want: false},
+		{name: "type mismatch", expr: `ScanStage > "x"`, wantErr: true},
+		{name: "division by zero", expr: `ScanStage / 0 > 1`, wantErr: true},
+		{name: "object equality", expr: `Session == Session`, wantErr: true},
+		{name: "array inequality", expr: `Items != "a"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := compileExpression(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := e.evalBool(env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %t, got: %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("want: %t, got: %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_compileExpressionErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`PilotRank ==`,
+		`(ScanStage > 1`,
+		`"unterminated`,
+		`ScanStage > 1 1`,
+		`unknown(ScanStage)`,
+		`contains(PilotRank)`,
+		`ScanStage # 1`,
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := compileExpression(expr); err == nil {
+				t.Fatalf("want an error for %q", expr)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package notifier
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/tommyblue/ED-AFK-Notifier/bots"
 )
@@ -68,6 +70,7 @@ func TestGotify_Send(t *testing.T) {
 		message        string
 		serverResponse int
 		expectError    bool
+		permanent      bool // the error is not worth retrying
 	}{
 		{
 			name:           "Successful notification",
@@ -86,6 +89,7 @@ func TestGotify_Send(t *testing.T) {
 			message:        "Test message",
 			serverResponse: http.StatusUnauthorized,
 			expectError:    true,
+			permanent:      true,
 		},
 		{
 			name:           "Empty message",
@@ -130,6 +134,99 @@ func TestGotify_Send(t *testing.T) {
 					t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
 				}
 			}
+
+			var permErr *bots.PermanentError
+			if errors.As(err, &permErr) != tt.permanent {
+				t.Errorf("Expected permanent error %v, got %v", tt.permanent, err)
+			}
+		})
+	}
+}
+
+func TestGotify_Check(t *testing.T) {
+	tests := []struct {
+		name         string
+		health       string
+		healthStatus int
+		tokenStatus  int
+		expectError  bool
+	}{
+		{
+			name:         "Healthy server and valid token",
+			health:       `{"health":"green","database":"green"}`,
+			healthStatus: http.StatusOK,
+			tokenStatus:  http.StatusBadRequest,
+			expectError:  false,
+		},
+		{
+			name:         "Unhealthy database",
+			health:       `{"health":"green","database":"red"}`,
+			healthStatus: http.StatusOK,
+			tokenStatus:  http.StatusBadRequest,
+			expectError:  true,
+		},
+		{
+			name:         "Health endpoint error",
+			healthStatus: http.StatusInternalServerError,
+			tokenStatus:  http.StatusBadRequest,
+			expectError:  true,
+		},
+		{
+			name:         "Invalid token",
+			health:       `{"health":"green","database":"green"}`,
+			healthStatus: http.StatusOK,
+			tokenStatus:  http.StatusUnauthorized,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/health":
+					w.WriteHeader(tt.healthStatus)
+					fmt.Fprint(w, tt.health)
+				case "/message":
+					w.WriteHeader(tt.tokenStatus)
+				default:
+					t.Errorf("Unexpected request to %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			g, err := bots.NewGotify(server.URL, "test-token", "Test Title", 5)
+			if err != nil {
+				t.Fatalf("Failed to create Gotify instance: %v", err)
+			}
+
+			err = g.Check()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Check() error = %v, expectError %v", err, tt.expectError)
+			}
 		})
 	}
 }
+
+func TestGotify_SendRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	g, err := bots.NewGotify(server.URL, "test-token", "Test Title", 5)
+	if err != nil {
+		t.Fatalf("Failed to create Gotify instance: %v", err)
+	}
+
+	err = g.Send("Test message")
+
+	var rateErr *bots.RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Expected a RateLimitError, got %v", err)
+	}
+	if rateErr.RetryAfter != 7*time.Second {
+		t.Errorf("Expected retry after 7s, got %s", rateErr.RetryAfter)
+	}
+}
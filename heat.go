@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// Default settings of the heat notifications
+const (
+	defaultHeatCooldown   = time.Minute
+	defaultHeatEscalation = 20 * time.Second
+)
+
+// HeatCfg defines how the heat warnings, repeating every few seconds while the ship is
+// overheating, are notified
+type HeatCfg struct {
+	Cooldown time.Duration // minimum time between two heat notifications, a longer pause ends the overheating
+	Escalate time.Duration // heat damage lasting this long is critical
+}
+
+// heatState tracks the overheating of the ship, using the time of the journal events
+type heatState struct {
+	lastNotified time.Time // last heat warning or damage notified
+	damageStart  time.Time // first heat damage of the current overheating
+	damageLast   time.Time
+	escalated    bool // the persisting heat damage has been notified as critical
+}
+
+func (c HeatCfg) cooldown() time.Duration {
+	if c.Cooldown <= 0 {
+		return defaultHeatCooldown
+	}
+
+	return c.Cooldown
+}
+
+func (c HeatCfg) escalate() time.Duration {
+	if c.Escalate <= 0 {
+		return defaultHeatEscalation
+	}
+
+	return c.Escalate
+}
+
+func heatWarningEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if ev.Time().Sub(e.heat.lastNotified) < e.cfg.Heat.cooldown() {
+		return nil
+	}
+	e.heat.lastNotified = ev.Time()
+
+	return e.notifyTemplate(heatWarningTemplate, categoryHeat, ev, skipNotify)
+}
+
+func heatDamageEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	ts := ev.Time()
+	h := &e.heat
+
+	// A pause longer than the cooldown ends the overheating
+	if ts.Sub(h.damageLast) >= e.cfg.Heat.cooldown() {
+		h.damageStart = ts
+		h.escalated = false
+	}
+	h.damageLast = ts
+
+	if d := ts.Sub(h.damageStart); !h.escalated && d >= e.cfg.Heat.escalate() {
+		h.escalated = true
+		h.lastNotified = ts
+		values := map[string]interface{}{"Seconds": int(d.Seconds())}
+		return e.notifyTemplateWith(heatCriticalTemplate, categoryCritical, ev, values, skipNotify)
+	}
+
+	if ts.Sub(h.lastNotified) < e.cfg.Heat.cooldown() && h.damageStart.Before(ts) {
+		return nil
+	}
+	h.lastNotified = ts
+
+	return e.notifyTemplate(heatDamageTemplate, categoryHeat, ev, skipNotify)
+}
+
+func systemsShutdownEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplate(systemsShutdownTemplate, categoryCritical, ev, skipNotify)
+}
+
+func rebootRepairEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplate(rebootRepairTemplate, categoryModules, ev, skipNotify)
+}
@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_heatEvents(t *testing.T) {
+	start := time.Date(2024, 3, 9, 22, 0, 0, 0, time.UTC)
+	header := func(event string, at time.Duration) journal.Header {
+		return journal.Header{Timestamp: start.Add(at), Event: event}
+	}
+	warning := func(at time.Duration) journal.Event {
+		return &journal.HeatWarning{Header: header(journal.HeatWarningEvent, at)}
+	}
+	damage := func(at time.Duration) journal.Event {
+		return &journal.HeatDamage{Header: header(journal.HeatDamageEvent, at)}
+	}
+
+	tests := []struct {
+		name    string
+		fn      eventFn
+		ev      journal.Event
+		wantMsg string
+	}{
+		{name: "first warning", fn: heatWarningEvent, ev: warning(0), wantMsg: "Ship is overheating!"},
+		{name: "repeated warning", fn: heatWarningEvent, ev: warning(5 * time.Second)},
+		{name: "first damage", fn: heatDamageEvent, ev: damage(10 * time.Second), wantMsg: "Heat damage! Modules are being damaged"},
+		{name: "repeated damage", fn: heatDamageEvent, ev: damage(20 * time.Second)},
+		{name: "persisting damage", fn: heatDamageEvent, ev: damage(30 * time.Second), wantMsg: "Heat damage for 20 seconds, modules are failing!"},
+		{name: "damage after escalation", fn: heatDamageEvent, ev: damage(35 * time.Second)},
+		{name: "warning after cooldown", fn: heatWarningEvent, ev: warning(2 * time.Minute), wantMsg: "Ship is overheating!"},
+		{name: "new overheating", fn: heatDamageEvent, ev: damage(2*time.Minute + 5*time.Second), wantMsg: "Heat damage! Modules are being damaged"},
+	}
+
+	n := &Notifier{cfg: &Cfg{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := tt.fn(n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func Test_rebootRepairEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []string
+		wantMsg string
+	}{
+		{name: "repaired", modules: []string{"ShieldGenerator", "MainEngines"}, wantMsg: "Reboot/repair completed: ShieldGenerator, MainEngines back online"},
+		{name: "nothing repaired", wantMsg: "Reboot/repair completed: no module repaired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n := &Notifier{cfg: &Cfg{}, bot: bot}
+
+			if err := rebootRepairEvent(n, &journal.RebootRepair{Modules: tt.modules}, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
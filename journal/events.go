@@ -0,0 +1,510 @@
+package journal
+
+import "time"
+
+// Names of the typed events
+const (
+	FileheaderEvent           = "Fileheader"
+	ContinuedEvent            = "Continued"
+	CommanderEvent            = "Commander"
+	LoadGameEvent             = "LoadGame"
+	HullDamageEvent           = "HullDamage"
+	DiedEvent                 = "Died"
+	ShieldStateEvent          = "ShieldState"
+	BountyEvent               = "Bounty"
+	MissionsEvent             = "Missions"
+	MissionAcceptedEvent      = "MissionAccepted"
+	MissionRedirectedEvent    = "MissionRedirected"
+	MissionCompletedEvent     = "MissionCompleted"
+	MissionAbandonedEvent     = "MissionAbandoned"
+	InterdictedEvent          = "Interdicted"
+	EscapeInterdictionEvent   = "EscapeInterdiction"
+	InterdictionEvent         = "Interdiction"
+	ShipTargetedEvent         = "ShipTargeted"
+	UnderAttackEvent          = "UnderAttack"
+	ScannedEvent              = "Scanned"
+	CargoEvent                = "Cargo"
+	EjectCargoEvent           = "EjectCargo"
+	CargoTransferEvent        = "CargoTransfer"
+	UndockedEvent             = "Undocked"
+	HeatWarningEvent          = "HeatWarning"
+	HeatDamageEvent           = "HeatDamage"
+	SystemsShutdownEvent      = "SystemsShutdown"
+	RebootRepairEvent         = "RebootRepair"
+	CommitCrimeEvent          = "CommitCrime"
+	CrimeVictimEvent          = "CrimeVictim"
+	PayBountiesEvent          = "PayBounties"
+	PayFinesEvent             = "PayFines"
+	LoadoutEvent              = "Loadout"
+	CrewAssignEvent           = "CrewAssign"
+	CrewMemberJoinsEvent      = "CrewMemberJoins"
+	CrewMemberRoleChangeEvent = "CrewMemberRoleChange"
+	NpcCrewRankEvent          = "NpcCrewRank"
+	NpcCrewPaidWageEvent      = "NpcCrewPaidWage"
+	RedeemVoucherEvent        = "RedeemVoucher"
+	FactionKillBondEvent      = "FactionKillBond"
+)
+
+func init() {
+	register(FileheaderEvent, func() Event { return &Fileheader{} })
+	register(ContinuedEvent, func() Event { return &Continued{} })
+	register(CommanderEvent, func() Event { return &Commander{} })
+	register(LoadGameEvent, func() Event { return &LoadGame{} })
+	register(HullDamageEvent, func() Event { return &HullDamage{} })
+	register(DiedEvent, func() Event { return &Died{} })
+	register(ShieldStateEvent, func() Event { return &ShieldState{} })
+	register(BountyEvent, func() Event { return &Bounty{} })
+	register(MissionsEvent, func() Event { return &Missions{} })
+	register(MissionAcceptedEvent, func() Event { return &MissionAccepted{} })
+	register(MissionRedirectedEvent, func() Event { return &MissionRedirected{} })
+	register(MissionCompletedEvent, func() Event { return &MissionCompleted{} })
+	register(MissionAbandonedEvent, func() Event { return &MissionAbandoned{} })
+	register(InterdictedEvent, func() Event { return &Interdicted{} })
+	register(EscapeInterdictionEvent, func() Event { return &EscapeInterdiction{} })
+	register(InterdictionEvent, func() Event { return &Interdiction{} })
+	register(ShipTargetedEvent, func() Event { return &ShipTargeted{} })
+	register(UnderAttackEvent, func() Event { return &UnderAttack{} })
+	register(ScannedEvent, func() Event { return &Scanned{} })
+	register(CargoEvent, func() Event { return &Cargo{} })
+	register(EjectCargoEvent, func() Event { return &EjectCargo{} })
+	register(CargoTransferEvent, func() Event { return &CargoTransfer{} })
+	register(UndockedEvent, func() Event { return &Undocked{} })
+	register(HeatWarningEvent, func() Event { return &HeatWarning{} })
+	register(HeatDamageEvent, func() Event { return &HeatDamage{} })
+	register(SystemsShutdownEvent, func() Event { return &SystemsShutdown{} })
+	register(RebootRepairEvent, func() Event { return &RebootRepair{} })
+	register(CommitCrimeEvent, func() Event { return &CommitCrime{} })
+	register(CrimeVictimEvent, func() Event { return &CrimeVictim{} })
+	register(PayBountiesEvent, func() Event { return &PayBounties{} })
+	register(PayFinesEvent, func() Event { return &PayFines{} })
+	register(LoadoutEvent, func() Event { return &Loadout{} })
+	register(CrewAssignEvent, func() Event { return &CrewAssign{} })
+	register(CrewMemberJoinsEvent, func() Event { return &CrewMemberJoins{} })
+	register(CrewMemberRoleChangeEvent, func() Event { return &CrewMemberRoleChange{} })
+	register(NpcCrewRankEvent, func() Event { return &NpcCrewRank{} })
+	register(NpcCrewPaidWageEvent, func() Event { return &NpcCrewPaidWage{} })
+	register(RedeemVoucherEvent, func() Event { return &RedeemVoucher{} })
+	register(FactionKillBondEvent, func() Event { return &FactionKillBond{} })
+}
+
+// Fileheader is the first event of every journal file
+type Fileheader struct {
+	Header
+	Part        int    `json:"part"` // incremented when a session continues in a new file
+	Language    string `json:"language"`
+	Odyssey     bool   `json:"Odyssey"`
+	GameVersion string `json:"gameversion"`
+	Build       string `json:"build"`
+}
+
+// Continued is the last event of a journal file when the session continues in a new file
+type Continued struct {
+	Header
+	Part int `json:"Part"` // part number of the next file
+}
+
+// Commander is written at startup, before LoadGame
+type Commander struct {
+	Header
+	FID  string `json:"FID"`
+	Name string `json:"Name"`
+}
+
+// LoadGame is written at startup, when the commander and the ship are loaded
+type LoadGame struct {
+	Header
+	Commander     string `json:"Commander"`
+	FID           string `json:"FID"`
+	Ship          string `json:"Ship"`
+	ShipLocalised string `json:"Ship_Localised"`
+	ShipID        int64  `json:"ShipID"`
+	ShipName      string `json:"ShipName"`
+	ShipIdent     string `json:"ShipIdent"`
+	GameMode      string `json:"GameMode"`
+	Credits       int64  `json:"Credits"`
+	Loan          int64  `json:"Loan"`
+	Horizons      bool   `json:"Horizons"`
+	Odyssey       bool   `json:"Odyssey"`
+}
+
+// HullDamage is written when the hull of the ship or of the fighter is damaged
+type HullDamage struct {
+	Header
+	Health      float64 `json:"Health"` // hull integrity, 0-1
+	PlayerPilot bool    `json:"PlayerPilot"`
+	Fighter     bool    `json:"Fighter"`
+}
+
+// Died is written when the ship is destroyed
+type Died struct {
+	Header
+	KillerName          string   `json:"KillerName"`
+	KillerNameLocalised string   `json:"KillerName_Localised"`
+	KillerShip          string   `json:"KillerShip"`
+	KillerRank          string   `json:"KillerRank"`
+	Killers             []Killer `json:"Killers"` // when killed by a wing
+}
+
+// Killer is a member of the wing that destroyed the ship
+type Killer struct {
+	Name string `json:"Name"`
+	Ship string `json:"Ship"`
+	Rank string `json:"Rank"`
+}
+
+// ShieldState is written when the shields go down or up again
+type ShieldState struct {
+	Header
+	ShieldsUp bool `json:"ShieldsUp"`
+}
+
+// Bounty is written when a wanted ship is destroyed
+type Bounty struct {
+	Header
+	Rewards                []BountyReward `json:"Rewards"`
+	Target                 string         `json:"Target"`
+	TargetLocalised        string         `json:"Target_Localised"`
+	TotalReward            int64          `json:"TotalReward"` // credits
+	VictimFaction          string         `json:"VictimFaction"`
+	VictimFactionLocalised string         `json:"VictimFaction_Localised"`
+	PilotName              string         `json:"PilotName"`
+	PilotNameLocalised     string         `json:"PilotName_Localised"`
+	SharedWithOthers       int            `json:"SharedWithOthers"`
+}
+
+// BountyReward is the reward of a faction for a bounty
+type BountyReward struct {
+	Faction string `json:"Faction"`
+	Reward  int64  `json:"Reward"`
+}
+
+// Missions lists the missions at startup
+type Missions struct {
+	Header
+	Active   []MissionStatus `json:"Active"`
+	Failed   []MissionStatus `json:"Failed"`
+	Complete []MissionStatus `json:"Complete"`
+}
+
+// MissionStatus is a mission listed by Missions
+type MissionStatus struct {
+	MissionID        int64  `json:"MissionID"`
+	Name             string `json:"Name"`
+	PassengerMission bool   `json:"PassengerMission"`
+	Expires          int64  `json:"Expires"` // seconds until the mission expires, 0 if expired
+}
+
+// MissionAccepted is written when a mission is accepted
+type MissionAccepted struct {
+	Header
+	Faction            string    `json:"Faction"`
+	Name               string    `json:"Name"`
+	LocalisedName      string    `json:"LocalisedName"`
+	MissionID          int64     `json:"MissionID"`
+	Expiry             time.Time `json:"Expiry"`
+	Wing               bool      `json:"Wing"`
+	Influence          string    `json:"Influence"`
+	Reputation         string    `json:"Reputation"`
+	Reward             int64     `json:"Reward"`
+	KillCount          int       `json:"KillCount"`
+	TargetFaction      string    `json:"TargetFaction"`
+	DestinationSystem  string    `json:"DestinationSystem"`
+	DestinationStation string    `json:"DestinationStation"`
+}
+
+// MissionRedirected is written when the objective of a mission is completed and the mission
+// must be handed in
+type MissionRedirected struct {
+	Header
+	MissionID             int64  `json:"MissionID"`
+	Name                  string `json:"Name"`
+	LocalisedName         string `json:"LocalisedName"`
+	NewDestinationStation string `json:"NewDestinationStation"`
+	NewDestinationSystem  string `json:"NewDestinationSystem"`
+	OldDestinationStation string `json:"OldDestinationStation"`
+	OldDestinationSystem  string `json:"OldDestinationSystem"`
+}
+
+// MissionCompleted is written when a mission is handed in
+type MissionCompleted struct {
+	Header
+	Faction       string `json:"Faction"`
+	Name          string `json:"Name"`
+	LocalisedName string `json:"LocalisedName"`
+	MissionID     int64  `json:"MissionID"`
+	TargetFaction string `json:"TargetFaction"`
+	Reward        int64  `json:"Reward"` // credits
+}
+
+// MissionAbandoned is written when a mission is abandoned
+type MissionAbandoned struct {
+	Header
+	Name          string `json:"Name"`
+	LocalisedName string `json:"LocalisedName"`
+	MissionID     int64  `json:"MissionID"`
+	Fine          int64  `json:"Fine"`
+}
+
+// Interdicted is written when the ship is pulled out of supercruise by another ship
+type Interdicted struct {
+	Header
+	Submitted            bool   `json:"Submitted"` // the commander submitted rather than losing the escape
+	Interdictor          string `json:"Interdictor"`
+	InterdictorLocalised string `json:"Interdictor_Localised"`
+	IsPlayer             bool   `json:"IsPlayer"`
+	IsThargoid           bool   `json:"IsThargoid"`
+	CombatRank           int    `json:"CombatRank"` // 0 (Harmless) to 8 (Elite)
+	Faction              string `json:"Faction"`
+	Power                string `json:"Power"`
+}
+
+// EscapeInterdiction is written when the ship escapes an interdiction
+type EscapeInterdiction struct {
+	Header
+	Interdictor          string `json:"Interdictor"`
+	InterdictorLocalised string `json:"Interdictor_Localised"`
+	IsPlayer             bool   `json:"IsPlayer"`
+	IsThargoid           bool   `json:"IsThargoid"`
+}
+
+// Interdiction is written when the commander interdicts another ship
+type Interdiction struct {
+	Header
+	Success              bool   `json:"Success"`
+	Interdicted          string `json:"Interdicted"`
+	InterdictedLocalised string `json:"Interdicted_Localised"`
+	IsPlayer             bool   `json:"IsPlayer"`
+	CombatRank           int    `json:"CombatRank"` // 0 (Harmless) to 8 (Elite)
+	Faction              string `json:"Faction"`
+	Power                string `json:"Power"`
+}
+
+// ShipTargeted is written when the commander targets a ship and at each scan stage. The pilot
+// is known from stage 1, the faction, legal status and health from stage 3.
+type ShipTargeted struct {
+	Header
+	TargetLocked       bool    `json:"TargetLocked"`
+	Ship               string  `json:"Ship"` // e.g. "anaconda"
+	ShipLocalised      string  `json:"Ship_Localised"`
+	ScanStage          int     `json:"ScanStage"`
+	PilotName          string  `json:"PilotName"` // e.g. "$cmdr_decorate:#name=Foo;" for players
+	PilotNameLocalised string  `json:"PilotName_Localised"`
+	PilotRank          string  `json:"PilotRank"`    // e.g. "Deadly"
+	ShieldHealth       float64 `json:"ShieldHealth"` // 0-100
+	HullHealth         float64 `json:"HullHealth"`   // 0-100
+	Faction            string  `json:"Faction"`
+	LegalStatus        string  `json:"LegalStatus"` // e.g. "Wanted"
+	Bounty             int64   `json:"Bounty"`
+}
+
+// UnderAttack is written when the ship, the fighter or the mothership is attacked
+type UnderAttack struct {
+	Header
+	Target string `json:"Target"` // "You", "Fighter" or "Mothership"
+}
+
+// Scanned is written when the ship is scanned by another ship
+type Scanned struct {
+	Header
+	ScanType string `json:"ScanType"` // "Cargo", "Crime", "Cabin", "Data" or "Unknown"
+}
+
+// Cargo is written at startup and when the cargo changes. The inventory is in the event only
+// at startup, otherwise it's in the Cargo.json file (see ReadCargo).
+type Cargo struct {
+	Header
+	Vessel    string      `json:"Vessel"` // "Ship" or "SRV"
+	Count     int         `json:"Count"`  // tons
+	Inventory []CargoItem `json:"Inventory"`
+}
+
+// CargoItem is a commodity in the cargo
+type CargoItem struct {
+	Name          string `json:"Name"`
+	NameLocalised string `json:"Name_Localised"`
+	Count         int    `json:"Count"`
+	Stolen        int    `json:"Stolen"`
+	MissionID     int64  `json:"MissionID"`
+}
+
+// EjectCargo is written when cargo is ejected or abandoned
+type EjectCargo struct {
+	Header
+	Type          string `json:"Type"`
+	TypeLocalised string `json:"Type_Localised"`
+	Count         int    `json:"Count"`
+	Abandoned     bool   `json:"Abandoned"`
+}
+
+// CargoTransfer is written when cargo is moved between the ship, the SRV and a fleet carrier
+type CargoTransfer struct {
+	Header
+	Transfers []Transfer `json:"Transfers"`
+}
+
+// Transfer is a commodity moved by CargoTransfer
+type Transfer struct {
+	Type          string `json:"Type"`
+	TypeLocalised string `json:"Type_Localised"`
+	Count         int    `json:"Count"`
+	Direction     string `json:"Direction"` // "toship", "tosrv" or "tocarrier"
+}
+
+// Undocked is written when the ship leaves a station
+type Undocked struct {
+	Header
+	StationName string `json:"StationName"`
+	StationType string `json:"StationType"`
+	MarketID    int64  `json:"MarketID"`
+}
+
+// HeatWarning is written when the heat of the ship goes above 100%
+type HeatWarning struct {
+	Header
+}
+
+// HeatDamage is written when the modules are damaged by heat above 150%
+type HeatDamage struct {
+	Header
+}
+
+// SystemsShutdown is written when the systems of the ship are shut down, e.g. by a Thargoid
+type SystemsShutdown struct {
+	Header
+}
+
+// RebootRepair is written when the reboot/repair sequence completes
+type RebootRepair struct {
+	Header
+	Modules []string `json:"Modules"` // modules repaired, e.g. "ShieldGenerator"
+}
+
+// CommitCrime is written when the commander commits a crime, punished with a fine or a bounty
+type CommitCrime struct {
+	Header
+	CrimeType       string `json:"CrimeType"` // e.g. "assault" or "fireInNoFireZone"
+	Faction         string `json:"Faction"`   // faction issuing the fine or the bounty
+	Victim          string `json:"Victim"`
+	VictimLocalised string `json:"Victim_Localised"`
+	Fine            int64  `json:"Fine"`
+	Bounty          int64  `json:"Bounty"`
+}
+
+// CrimeVictim is written when a crime is committed against the commander
+type CrimeVictim struct {
+	Header
+	Offender          string `json:"Offender"`
+	OffenderLocalised string `json:"Offender_Localised"`
+	CrimeType         string `json:"CrimeType"`
+	Bounty            int64  `json:"Bounty"`
+}
+
+// PayBounties is written when the commander pays the bounties of a faction
+type PayBounties struct {
+	Header
+	Amount           int64   `json:"Amount"`
+	Faction          string  `json:"Faction"`
+	FactionLocalised string  `json:"Faction_Localised"`
+	ShipID           int64   `json:"ShipID"`
+	BrokerPercentage float64 `json:"BrokerPercentage"`
+}
+
+// PayFines is written when the commander pays the fines
+type PayFines struct {
+	Header
+	Amount           int64   `json:"Amount"`
+	AllFines         bool    `json:"AllFines"`
+	Faction          string  `json:"Faction"`
+	ShipID           int64   `json:"ShipID"`
+	BrokerPercentage float64 `json:"BrokerPercentage"`
+}
+
+// Loadout is written at startup and when the ship or its modules change
+type Loadout struct {
+	Header
+	Ship          string   `json:"Ship"` // e.g. "type9_military"
+	ShipID        int64    `json:"ShipID"`
+	ShipName      string   `json:"ShipName"`
+	ShipIdent     string   `json:"ShipIdent"`
+	HullValue     int64    `json:"HullValue"`
+	ModulesValue  int64    `json:"ModulesValue"`
+	HullHealth    float64  `json:"HullHealth"` // 0-1
+	CargoCapacity int      `json:"CargoCapacity"`
+	Rebuy         int64    `json:"Rebuy"` // insurance cost of the ship
+	Modules       []Module `json:"Modules"`
+}
+
+// Module is a module fitted on the ship
+type Module struct {
+	Slot     string  `json:"Slot"` // e.g. "Slot01_Size8"
+	Item     string  `json:"Item"` // e.g. "int_fighterbay_size5_class1"
+	On       bool    `json:"On"`
+	Priority int     `json:"Priority"`
+	Health   float64 `json:"Health"` // 0-1
+	Value    int64   `json:"Value"`
+}
+
+// CrewAssign is written when the role of a NPC crew member changes
+type CrewAssign struct {
+	Header
+	Name   string `json:"Name"`
+	CrewID int64  `json:"CrewID"`
+	Role   string `json:"Role"` // "Active" or "Idle"
+}
+
+// CrewMemberJoins is written when a commander joins the crew of the ship in multicrew
+type CrewMemberJoins struct {
+	Header
+	Crew         string `json:"Crew"`
+	Telepresence bool   `json:"Telepresence"`
+}
+
+// CrewMemberRoleChange is written when a commander of the crew changes role in multicrew
+type CrewMemberRoleChange struct {
+	Header
+	Crew         string `json:"Crew"`
+	Role         string `json:"Role"` // e.g. "FighterCon"
+	Telepresence bool   `json:"Telepresence"`
+}
+
+// NpcCrewRank is written when a NPC crew member is promoted
+type NpcCrewRank struct {
+	Header
+	NpcCrewName string `json:"NpcCrewName"`
+	NpcCrewID   int64  `json:"NpcCrewId"`
+	RankCombat  int    `json:"RankCombat"` // 0 (Harmless) to 8 (Elite)
+}
+
+// NpcCrewPaidWage is written when a NPC crew member is paid its share of the earnings
+type NpcCrewPaidWage struct {
+	Header
+	NpcCrewName string `json:"NpcCrewName"`
+	NpcCrewID   int64  `json:"NpcCrewId"`
+	Amount      int64  `json:"Amount"`
+}
+
+// RedeemVoucher is written when vouchers are redeemed at a station
+type RedeemVoucher struct {
+	Header
+	Type             string           `json:"Type"`   // e.g. "bounty" or "CombatBond"
+	Amount           int64            `json:"Amount"` // credits paid
+	Faction          string           `json:"Faction"`
+	Factions         []VoucherFaction `json:"Factions"` // bounties, by faction
+	BrokerPercentage float64          `json:"BrokerPercentage"`
+}
+
+// VoucherFaction is the amount redeemed for a faction by RedeemVoucher
+type VoucherFaction struct {
+	Faction string `json:"Faction"`
+	Amount  int64  `json:"Amount"`
+}
+
+// FactionKillBond is written when a ship is destroyed in a conflict zone, instead of Bounty
+type FactionKillBond struct {
+	Header
+	Reward                   int64  `json:"Reward"` // credits
+	AwardingFaction          string `json:"AwardingFaction"`
+	AwardingFactionLocalised string `json:"AwardingFaction_Localised"`
+	VictimFaction            string `json:"VictimFaction"`
+	VictimFactionLocalised   string `json:"VictimFaction_Localised"`
+}
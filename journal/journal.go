@@ -0,0 +1,207 @@
+// Package journal decodes the events of the Elite Dangerous player journal into typed structs.
+//
+// The journal is a sequence of JSON objects, one per line, each with a "timestamp" and an
+// "event" field. Decode returns the struct registered for the event (e.g. *HullDamage) or
+// *Unknown for the events without a struct. The fields of the line that aren't mapped by the
+// struct are preserved in the Extra field of the Header, so that Marshal can encode the event
+// back without losing anything. The fields of the structs are always encoded, even when
+// missing from the original line, so that templates can rely on them.
+//
+// See https://elite-journal.readthedocs.io/ for the documentation of the events.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a journal event. Every event struct embeds Header.
+type Event interface {
+	// EventName returns the name of the event, e.g. "HullDamage"
+	EventName() string
+	// Time returns the timestamp of the event
+	Time() time.Time
+
+	header() *Header
+}
+
+// Header contains the fields common to every journal event
+type Header struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     string    `json:"event"`
+
+	// Extra contains the fields of the event not mapped by its struct, keyed by their journal
+	// name. For *Unknown events, it contains every field but the timestamp and the event name.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (h Header) EventName() string { return h.Event }
+func (h Header) Time() time.Time   { return h.Timestamp }
+func (h *Header) header() *Header  { return h }
+
+// Unknown is an event without a struct, its fields are in Extra
+type Unknown struct {
+	Header
+}
+
+// eventTypes contains the constructors of the typed events, keyed by event name
+var eventTypes = map[string]func() Event{}
+
+func register(name string, fn func() Event) {
+	eventTypes[name] = fn
+}
+
+// New returns an empty event of the struct registered for the name, or *Unknown
+func New(name string) Event {
+	if fn, ok := eventTypes[name]; ok {
+		ev := fn()
+		ev.header().Event = name
+		return ev
+	}
+
+	return &Unknown{Header: Header{Event: name}}
+}
+
+// Decode decodes a line of the journal
+func Decode(line []byte) (Event, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, err
+	}
+
+	var h Header
+	if err := json.Unmarshal(line, &h); err != nil {
+		return nil, err
+	}
+	if h.Event == "" {
+		return nil, errors.New("missing event name")
+	}
+
+	ev := New(h.Event)
+	if err := json.Unmarshal(line, ev); err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %v", h.Event, err)
+	}
+
+	known := knownFields(reflect.TypeOf(ev).Elem())
+	for name := range fields {
+		if known[strings.ToLower(name)] {
+			delete(fields, name)
+		}
+	}
+	if len(fields) > 0 {
+		ev.header().Extra = fields
+	}
+
+	return ev, nil
+}
+
+// Marshal encodes the event, including the fields in Extra
+func Marshal(ev Event) ([]byte, error) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := ev.header().Extra
+	if len(extra) == 0 {
+		return b, nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for name, v := range extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = v
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// Fields returns every field of the event, including those in Extra, keyed by journal name
+// and decoded as by encoding/json into an interface{}
+func Fields(ev Event) (map[string]interface{}, error) {
+	b, err := Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// Decoder reads and decodes the events of a journal, one per line. Lines have no length
+// limit, as some events (e.g. Loadout) can be very long.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next event, or io.EOF at the end of the input. Blank lines are skipped.
+// When a line can't be decoded an error is returned, and the next call continues with the
+// following line.
+func (d *Decoder) Decode() (Event, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			return Decode(line)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+var knownFieldsCache sync.Map // reflect.Type -> map[string]bool
+
+// knownFields returns the lowercase journal names of the fields of the struct, including those
+// of the embedded structs. Names are lowercase because encoding/json matches them ignoring case.
+func knownFields(t reflect.Type) map[string]bool {
+	if known, ok := knownFieldsCache.Load(t); ok {
+		return known.(map[string]bool)
+	}
+
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for name := range knownFields(f.Type) {
+				known[name] = true
+			}
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	knownFieldsCache.Store(t, known)
+
+	return known
+}
@@ -0,0 +1,167 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// Test_decodeGolden decodes the sample journals in testdata, comparing the decoded events with
+// the .golden files. Run `go test ./journal -update` to regenerate them.
+func Test_decodeGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var b strings.Builder
+			d := NewDecoder(f)
+			for {
+				ev, err := d.Decode()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				typed, err := json.MarshalIndent(ev, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				fmt.Fprintf(&b, "%T %s\nextra: %s\n\n", ev, typed, extraNames(ev))
+			}
+
+			golden := strings.TrimSuffix(file, ".log") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(b.String()), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != string(want) {
+				t.Fatalf("decoded events differ from %s, got:\n%s", golden, b.String())
+			}
+		})
+	}
+}
+
+// Test_roundTrip verifies that encoding a decoded event gives back every field of the line
+func Test_roundTrip(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "Journal.2024-03-09T201233.01.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var want map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &want); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(want["event"].(string), func(t *testing.T) {
+			ev, err := Decode([]byte(line))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Fields(ev)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, v := range want {
+				if !reflect.DeepEqual(got[name], v) {
+					t.Fatalf("field %s, want: %v, got: %v", name, v, got[name])
+				}
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantType string
+		wantErr  bool
+	}{
+		{name: "typed", line: `{"timestamp":"2024-03-09T21:02:17Z","event":"ShieldState","ShieldsUp":true}`, wantType: "*journal.ShieldState"},
+		{name: "unknown", line: `{"timestamp":"2024-03-09T21:02:17Z","event":"FSDJump","StarSystem":"Sol"}`, wantType: "*journal.Unknown"},
+		{name: "missing event", line: `{"timestamp":"2024-03-09T21:02:17Z"}`, wantErr: true},
+		{name: "wrong field type", line: `{"timestamp":"2024-03-09T21:02:17Z","event":"HullDamage","Health":"full"}`, wantErr: true},
+		{name: "truncated", line: `{"timestamp":"2024-03-09T21:02:17Z","event":"Hull`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := Decode([]byte(tt.line))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %t, got: %v", tt.wantErr, err)
+			}
+			if err == nil && fmt.Sprintf("%T", ev) != tt.wantType {
+				t.Fatalf("want: %s, got: %T", tt.wantType, ev)
+			}
+		})
+	}
+}
+
+func TestDecoder_longLines(t *testing.T) {
+	long := strings.Repeat("x", 200*1024)
+	input := `{"timestamp":"2024-03-09T21:02:17Z","event":"Loadout","Ship":"` + long + `"}` + "\n\n" +
+		`{"timestamp":"2024-03-09T21:02:18Z","event":"ShieldState","ShieldsUp":false}`
+
+	d := NewDecoder(strings.NewReader(input))
+
+	ev, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, ok := ev.(*Loadout); !ok || len(l.Ship) != len(long) {
+		t.Fatalf("unexpected event %s", ev.EventName())
+	}
+
+	ev, err = d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ev.(*ShieldState); !ok {
+		t.Fatalf("want a ShieldState event, got %T", ev)
+	}
+
+	if _, err := d.Decode(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF, got: %v", err)
+	}
+}
+
+func extraNames(ev Event) string {
+	var names []string
+	for name := range ev.header().Extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
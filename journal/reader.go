@@ -0,0 +1,353 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often Follow checks for new lines and journal files
+const DefaultPollInterval = time.Second
+
+// modTimeGranularity is the coarsest resolution of the modification times, e.g. on FAT
+const modTimeGranularity = 2 * time.Second
+
+// Position is the position of a line in the journal
+type Position struct {
+	File   string // name of the journal file, e.g. "Journal.2024-03-09T201233.01.log"
+	Offset int64  // byte offset of the line in the file
+}
+
+// Entry is an event read from the journal
+type Entry struct {
+	Event    Event
+	Position Position // position of the line of the event
+}
+
+// DecodeError is returned by the Reader for the lines that can't be decoded. Reading can
+// continue with the next line.
+type DecodeError struct {
+	Position Position
+	Line     string
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.Position.File, e.Position.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the events of the journal files in a directory incrementally, keeping track
+// of the position of each line so that reading can be resumed later. Lines still being
+// written are returned only once complete and, at the end of a file, the reader moves to
+// the next journal file written by the game.
+type Reader struct {
+	PollInterval time.Duration // interval of the checks of Follow, DefaultPollInterval when 0
+
+	dir     string
+	file    string
+	f       *os.File
+	r       *bufio.Reader
+	offset  int64  // offset of the next line
+	partial []byte // beginning of a line still being written
+
+	files      []string  // journal files of dir, listed again only when dir changes
+	dirModTime time.Time // modification time of dir when files was listed
+	listedAt   time.Time // when files was listed
+}
+
+// OpenReader returns a reader of the journal files in dir starting from the position. When
+// the position has no file, the reader starts from the beginning of the latest journal file.
+func OpenReader(dir string, pos Position) (*Reader, error) {
+	if pos.File == "" {
+		latest, err := LatestFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		pos = Position{File: latest}
+	}
+
+	r := &Reader{dir: dir}
+	if err := r.open(pos); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Reader) open(pos Position) error {
+	f, err := os.Open(filepath.Join(r.dir, pos.File))
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if pos.Offset > fi.Size() {
+		f.Close()
+		return fmt.Errorf("offset %d is past the end of %s", pos.Offset, pos.File)
+	}
+
+	if _, err := f.Seek(pos.Offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+
+	if r.f != nil {
+		r.f.Close()
+	}
+	r.file = pos.File
+	r.f = f
+	r.r = bufio.NewReader(f)
+	r.offset = pos.Offset
+	r.partial = nil
+
+	return nil
+}
+
+// Position returns the position of the next line, to resume reading with OpenReader
+func (r *Reader) Position() Position {
+	return Position{File: r.file, Offset: r.offset}
+}
+
+// Close closes the current journal file
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+// Next returns the next event, or io.EOF when there are no complete lines to read and the
+// game hasn't started a new journal file. Lines that can't be decoded return a *DecodeError.
+func (r *Reader) Next() (Entry, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Entry{}, err
+		}
+
+		if err == nil {
+			if len(r.partial) > 0 {
+				line = append(r.partial, line...)
+				r.partial = nil
+			}
+			if entry, ok, err := r.decode(line); ok || err != nil {
+				return entry, err
+			}
+			continue
+		}
+
+		// End of the file, keep the incomplete line for the next read
+		r.partial = append(r.partial, line...)
+
+		next, err := r.nextFile()
+		if err != nil {
+			return Entry{}, err
+		}
+		if next == "" {
+			return Entry{}, io.EOF
+		}
+
+		// The game has moved to a new file, the last line of this one is complete even
+		// without the newline
+		partial := r.partial
+		r.partial = nil
+		entry, ok, decodeErr := r.decode(partial)
+
+		if err := r.open(Position{File: next}); err != nil {
+			return Entry{}, err
+		}
+		if ok || decodeErr != nil {
+			return entry, decodeErr
+		}
+	}
+}
+
+// decode decodes the line at the current offset, moving the offset after it. Blank lines
+// are skipped, returning false.
+func (r *Reader) decode(line []byte) (Entry, bool, error) {
+	pos := r.Position()
+	r.offset += int64(len(line))
+
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 {
+		return Entry{}, false, nil
+	}
+
+	ev, err := Decode(trimmed)
+	if err != nil {
+		return Entry{Position: pos}, false, &DecodeError{Position: pos, Line: string(trimmed), Err: err}
+	}
+
+	return Entry{Event: ev, Position: pos}, true, nil
+}
+
+// nextFile returns the journal file written after the current one, or an empty string
+func (r *Reader) nextFile() (string, error) {
+	files, err := r.listFiles()
+	if err != nil {
+		return "", err
+	}
+
+	for i, f := range files {
+		if f == r.file && i+1 < len(files) {
+			return files[i+1], nil
+		}
+	}
+
+	return "", nil
+}
+
+// listFiles returns the journal files of dir, listing them again only when the modification
+// time of dir changes. A file created right after the listing may leave the same modification
+// time, so the listing is trusted only when done a while after the last change.
+func (r *Reader) listFiles() ([]string, error) {
+	fi, err := os.Stat(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	if r.files != nil && fi.ModTime().Equal(r.dirModTime) && r.listedAt.Sub(r.dirModTime) > modTimeGranularity {
+		return r.files, nil
+	}
+
+	listedAt := time.Now()
+	files, err := Files(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	r.files, r.dirModTime, r.listedAt = files, fi.ModTime(), listedAt
+
+	return files, nil
+}
+
+// Follow returns the next event like Next, but waits for new lines and journal files until
+// the context is done
+func (r *Reader) Follow(ctx context.Context) (Entry, error) {
+	poll := r.PollInterval
+	if poll <= 0 {
+		poll = DefaultPollInterval
+	}
+
+	for {
+		entry, err := r.Next()
+		if !errors.Is(err, io.EOF) {
+			return entry, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return Entry{}, ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
+
+// Files returns the names of the journal files in dir, from the oldest to the most
+// recently written
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type journalFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []journalFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "Journal") || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, journalFile{name: entry.Name(), modTime: fi.ModTime()})
+	}
+
+	// Names contain the creation time, use them when files have the same modification time
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].modTime.Equal(files[j].modTime) {
+			return files[i].name < files[j].name
+		}
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+
+	return names, nil
+}
+
+// LatestFile returns the name of the most recently written journal file in dir
+func LatestFile(dir string) (string, error) {
+	files, err := Files(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("cannot find the journal file")
+	}
+
+	return files[len(files)-1], nil
+}
+
+// ReadFileheader returns the Fileheader event at the beginning of the journal file
+func ReadFileheader(path string) (*Fileheader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ev, err := NewDecoder(f).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	header, ok := ev.(*Fileheader)
+	if !ok {
+		return nil, fmt.Errorf("%s starts with %s rather than Fileheader", filepath.Base(path), ev.EventName())
+	}
+
+	return header, nil
+}
+
+// CargoFile is the name of the file with the current cargo of the ship, in the journal directory
+const CargoFile = "Cargo.json"
+
+// ReadCargo returns the current cargo, from the Cargo.json file in dir
+func ReadCargo(dir string) (*Cargo, error) {
+	b, err := os.ReadFile(filepath.Join(dir, CargoFile))
+	if err != nil {
+		return nil, err
+	}
+
+	ev, err := Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %v", CargoFile, err)
+	}
+
+	cargo, ok := ev.(*Cargo)
+	if !ok {
+		return nil, fmt.Errorf("%s contains %s rather than Cargo", CargoFile, ev.EventName())
+	}
+
+	return cargo, nil
+}
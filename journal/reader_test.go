@@ -0,0 +1,232 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	shieldsDown = `{"timestamp":"2024-03-09T21:02:17Z","event":"ShieldState","ShieldsUp":false}` + "\n"
+	shieldsUp   = `{"timestamp":"2024-03-09T21:03:30Z","event":"ShieldState","ShieldsUp":true}` + "\n"
+	hullDamage  = `{"timestamp":"2024-03-09T21:02:21Z","event":"HullDamage","Health":0.85,"PlayerPilot":true,"Fighter":false}` + "\n"
+)
+
+func writeJournal(t *testing.T, dir, name, content string, modTime time.Time) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendJournal(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	dir := t.TempDir()
+	name := "Journal.2024-03-09T201233.01.log"
+	writeJournal(t, dir, name, shieldsDown+"not json\n"+hullDamage[:30], time.Now())
+
+	r, err := OpenReader(dir, Position{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	entry, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Position != (Position{File: name, Offset: 0}) || entry.Event.EventName() != ShieldStateEvent {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	var decodeErr *DecodeError
+	if _, err := r.Next(); !errors.As(err, &decodeErr) || decodeErr.Position.Offset != int64(len(shieldsDown)) {
+		t.Fatalf("want a decode error at %d, got: %v", len(shieldsDown), err)
+	}
+
+	// The last line is still being written
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF, got: %v", err)
+	}
+	saved := r.Position()
+
+	appendJournal(t, dir, name, hullDamage[30:])
+	entry, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Position.Offset != saved.Offset || entry.Event.EventName() != HullDamageEvent {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	// Resume from the saved position
+	resumed, err := OpenReader(dir, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+
+	entry, err = resumed.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Position != saved || entry.Event.EventName() != HullDamageEvent {
+		t.Fatalf("unexpected resumed entry %+v", entry)
+	}
+	if resumed.Position() != r.Position() {
+		t.Fatalf("want position %+v, got: %+v", r.Position(), resumed.Position())
+	}
+}
+
+func TestReader_rotation(t *testing.T) {
+	dir := t.TempDir()
+	first := "Journal.2024-03-09T201233.01.log"
+	second := "Journal.2024-03-10T000000.02.log"
+	now := time.Now()
+
+	// The last line of the first file has no newline
+	writeJournal(t, dir, first, shieldsDown+strings.TrimSuffix(hullDamage, "\n"), now.Add(-time.Hour))
+
+	r, err := OpenReader(dir, Position{File: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.PollInterval = 10 * time.Millisecond
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF, got: %v", err)
+	}
+
+	writeJournal(t, dir, second, shieldsUp, now)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	want := []Position{
+		{File: first, Offset: int64(len(shieldsDown))},
+		{File: second, Offset: 0},
+	}
+	for _, pos := range want {
+		entry, err := r.Follow(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Position != pos {
+			t.Fatalf("want position %+v, got: %+v", pos, entry.Position)
+		}
+	}
+
+	if _, err := r.Follow(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestReader_cachedFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := "Journal.2024-03-09T201233.01.log"
+	second := "Journal.2024-03-10T000000.02.log"
+	old := time.Now().Add(-time.Hour)
+
+	writeJournal(t, dir, first, shieldsDown, old)
+	setModTime := func(modTime time.Time) {
+		t.Helper()
+		if err := os.Chtimes(dir, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setModTime(old)
+
+	r, err := OpenReader(dir, Position{File: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF, got: %v", err)
+	}
+
+	// The directory looks unchanged, the cached listing is used
+	writeJournal(t, dir, second, shieldsUp, time.Now())
+	setModTime(old)
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF with the cached listing, got: %v", err)
+	}
+
+	setModTime(time.Now())
+	entry, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Position.File != second {
+		t.Fatalf("want an event of %s, got: %+v", second, entry.Position)
+	}
+}
+
+func TestOpenReader_errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := OpenReader(dir, Position{}); err == nil {
+		t.Fatal("want an error without journal files")
+	}
+
+	name := "Journal.2024-03-09T201233.01.log"
+	writeJournal(t, dir, name, shieldsDown, time.Now())
+	if _, err := OpenReader(dir, Position{File: name, Offset: 1000}); err == nil {
+		t.Fatal("want an error for an offset past the end of the file")
+	}
+}
+
+func TestReadCargo(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadCargo(dir); err == nil {
+		t.Fatalf("expected error without %s", CargoFile)
+	}
+
+	writeJournal(t, dir, CargoFile, `{ "timestamp":"2024-03-09T20:14:02Z", "event":"Cargo", "Vessel":"Ship", "Count":4, "Inventory":[
+{ "Name":"gold", "Name_Localised":"Gold", "Count":4, "Stolen":0 }
+] }`, time.Now())
+
+	cargo, err := ReadCargo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cargo.Count != 4 || len(cargo.Inventory) != 1 || cargo.Inventory[0].NameLocalised != "Gold" {
+		t.Fatalf("want: 4 t of Gold, got: %+v", cargo)
+	}
+
+	writeJournal(t, dir, CargoFile, shieldsDown, time.Now())
+	if _, err := ReadCargo(dir); err == nil {
+		t.Fatalf("expected error with another event")
+	}
+}
@@ -0,0 +1,230 @@
+package notifier
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// Steam app ID of Elite Dangerous, used to find the Proton prefix
+const eliteSteamAppID = "359320"
+
+// Path of the journal directory relative to the user profile (Windows) or to the user
+// directory inside a Wine/Proton prefix
+var savedGamesPath = filepath.Join("Saved Games", "Frontier Developments", "Elite Dangerous")
+
+type journalCandidate struct {
+	path        string
+	source      string    // where the candidate comes from, e.g. "steam" or "lutris"
+	lastJournal time.Time // modification time of the most recent journal file
+}
+
+// ResolveJournalPath returns the journal path to use. When path is empty or "auto" the
+// directory is detected looking into Steam libraries, Lutris/Heroic prefixes and the
+// Windows user profile.
+func ResolveJournalPath(path string) (string, error) {
+	if path != "" && !strings.EqualFold(path, "auto") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot detect the journal path: %v", err)
+	}
+
+	return detectJournalPath(home)
+}
+
+func detectJournalPath(home string) (string, error) {
+	var valid []journalCandidate
+
+	for _, c := range journalCandidates(home) {
+		mod, err := latestJournalModTime(c.path)
+		if err != nil {
+			log.Infof("Journal path candidate rejected (%s): %s: %v", c.source, c.path, err)
+			continue
+		}
+
+		c.lastJournal = mod
+		valid = append(valid, c)
+	}
+
+	if len(valid) == 0 {
+		return "", fmt.Errorf("cannot detect the journal path, please set it in the config file")
+	}
+
+	// Prefer the directory with the most recently written journal
+	sort.SliceStable(valid, func(i, j int) bool {
+		return valid[i].lastJournal.After(valid[j].lastJournal)
+	})
+
+	for _, c := range valid[1:] {
+		log.Infof("Journal path candidate rejected (%s): %s: its last journal (%s) is older than the chosen one",
+			c.source, c.path, c.lastJournal.Format(time.RFC3339))
+	}
+	log.Infof("Journal path detected (%s): %s", valid[0].source, valid[0].path)
+
+	return valid[0].path, nil
+}
+
+func latestJournalModTime(path string) (time.Time, error) {
+	j, err := journal.LatestFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	fi, err := os.Stat(filepath.Join(path, j))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return fi.ModTime(), nil
+}
+
+func journalCandidates(home string) []journalCandidate {
+	var candidates []journalCandidate
+
+	for _, lib := range steamLibraries(home) {
+		candidates = append(candidates, journalCandidate{
+			path:   filepath.Join(lib, "steamapps", "compatdata", eliteSteamAppID, "pfx", "drive_c", "users", "steamuser", savedGamesPath),
+			source: "steam",
+		})
+	}
+
+	// Lutris installs games in ~/Games/<game>, Heroic in ~/Games/Heroic/Prefixes/<game>
+	prefixes := []journalCandidate{
+		{path: filepath.Join(home, "Games", "*"), source: "lutris"},
+		{path: filepath.Join(home, "Games", "Heroic", "Prefixes", "*"), source: "heroic"},
+		{path: filepath.Join(home, "Games", "Heroic", "Prefixes", "default", "*"), source: "heroic"},
+	}
+	for _, prefix := range prefixes {
+		matches, _ := filepath.Glob(filepath.Join(prefix.path, "drive_c", "users", "*", savedGamesPath))
+		for _, m := range matches {
+			candidates = append(candidates, journalCandidate{path: m, source: prefix.source})
+		}
+	}
+
+	candidates = append(candidates, journalCandidate{
+		path:   filepath.Join(home, savedGamesPath),
+		source: "windows",
+	})
+
+	return candidates
+}
+
+// steamLibraries returns the Steam library folders found in the known Steam installation
+// directories, reading their libraryfolders.vdf
+func steamLibraries(home string) []string {
+	roots := []string{
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam"),
+	}
+
+	seen := make(map[string]bool)
+	var libraries []string
+	add := func(p string) {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			p = resolved
+		}
+		if !seen[p] {
+			seen[p] = true
+			libraries = append(libraries, p)
+		}
+	}
+
+	for _, root := range roots {
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		add(root)
+
+		f, err := os.Open(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+		if err != nil {
+			log.Debugf("Cannot read Steam library folders: %v", err)
+			continue
+		}
+
+		paths, err := parseLibraryFolders(f)
+		f.Close()
+		if err != nil {
+			log.Debugf("Cannot parse Steam library folders: %v", err)
+		}
+		for _, p := range paths {
+			add(p)
+		}
+	}
+
+	return libraries
+}
+
+// parseLibraryFolders returns the values of the "path" keys of a Steam libraryfolders.vdf file
+func parseLibraryFolders(r io.Reader) ([]string, error) {
+	var (
+		paths   []string
+		lastKey string
+		isValue bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		for _, token := range vdfTokens(scanner.Text()) {
+			switch token {
+			case "{", "}":
+				isValue = false
+				continue
+			}
+
+			if isValue && lastKey == "path" {
+				paths = append(paths, token)
+			}
+			if !isValue {
+				lastKey = strings.ToLower(token)
+			}
+			isValue = !isValue
+		}
+	}
+
+	return paths, scanner.Err()
+}
+
+// vdfTokens splits a line of a VDF file in quoted strings and braces
+func vdfTokens(line string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			if quoted {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			quoted = !quoted
+		case quoted:
+			current.WriteRune(r)
+		case r == '{' || r == '}':
+			tokens = append(tokens, string(r))
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const libraryFoldersVdf = `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"/home/cmdr/.local/share/Steam"
+		"label"		""
+		"apps"
+		{
+			"228980"		"511651385"
+		}
+	}
+	"1"
+	{
+		"path"		"D:\\SteamLibrary"
+		"label"		"games"
+		"apps"
+		{
+			"359320"		"18234567812"
+		}
+	}
+}
+`
+
+func Test_parseLibraryFolders(t *testing.T) {
+	paths, err := parseLibraryFolders(strings.NewReader(libraryFoldersVdf))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"/home/cmdr/.local/share/Steam", `D:\SteamLibrary`}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("want: %v, got: %v", want, paths)
+	}
+}
+
+func Test_detectJournalPath(t *testing.T) {
+	home := t.TempDir()
+
+	steamRoot := filepath.Join(home, ".local", "share", "Steam")
+	library := filepath.Join(home, "SteamLibrary")
+	vdf := `"libraryfolders" { "0" { "path" "` + steamRoot + `" } "1" { "path" "` + library + `" } }`
+	writeFile(t, filepath.Join(steamRoot, "steamapps", "libraryfolders.vdf"), vdf, time.Now())
+
+	prefix := filepath.Join("steamapps", "compatdata", eliteSteamAppID, "pfx", "drive_c", "users", "steamuser", savedGamesPath)
+	oldJournals := filepath.Join(steamRoot, prefix)
+	newJournals := filepath.Join(library, prefix)
+	writeFile(t, filepath.Join(oldJournals, "Journal.2022-10-01T100000.01.log"), "", time.Now().Add(-48*time.Hour))
+	writeFile(t, filepath.Join(newJournals, "Journal.2022-10-03T100000.01.log"), "", time.Now())
+
+	// Lutris prefix without journal files
+	if err := os.MkdirAll(filepath.Join(home, "Games", "elite-dangerous", "drive_c", "users", "cmdr", savedGamesPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := detectJournalPath(home)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != newJournals {
+		t.Fatalf("want: %s, got: %s", newJournals, got)
+	}
+
+	if _, err := detectJournalPath(t.TempDir()); err == nil {
+		t.Fatalf("expected error with no candidates")
+	}
+}
+
+func writeFile(t *testing.T, path, content string, mod time.Time) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
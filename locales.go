@@ -0,0 +1,350 @@
+package notifier
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// supportedLanguages are the languages with a translation of every notification
+var supportedLanguages = []language.Tag{
+	language.English,
+	language.Italian,
+	language.German,
+	language.French,
+	language.Spanish,
+}
+
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+func init() {
+	// Some languages separate the percent sign from the number
+	message.SetString(language.German, "%d%%", "%d %%")
+	message.SetString(language.French, "%d%%", "%d %%")
+	message.SetString(language.Spanish, "%d%%", "%d %%")
+
+	for lang, ranks := range localizedCombatRanks {
+		for i, rank := range ranks {
+			message.SetString(lang, combatRanks[i], rank)
+		}
+	}
+	for lang, crimes := range localizedCrimeTypes {
+		for crime, text := range crimes {
+			message.SetString(lang, crime, text)
+		}
+	}
+}
+
+// localizedCombatRanks are the names of the combat ranks, in the order of combatRanks
+var localizedCombatRanks = map[language.Tag][]string{
+	language.Italian: {"Innocuo", "Quasi innocuo", "Novizio", "Competente", "Esperto", "Maestro", "Pericoloso", "Letale", "Elite"},
+	language.German:  {"Harmlos", "Zumeist harmlos", "Anfänger", "Kompetent", "Experte", "Meister", "Gefährlich", "Tödlich", "Elite"},
+	language.French:  {"Inoffensif", "Presque inoffensif", "Novice", "Compétent", "Expert", "Maître", "Dangereux", "Mortel", "Élite"},
+	language.Spanish: {"Inofensivo", "Casi inofensivo", "Novato", "Competente", "Experto", "Maestro", "Peligroso", "Letal", "Élite"},
+}
+
+// localizedCrimeTypes are the translations of the most common crime types, keyed by the
+// English words returned by formatCrimeType
+var localizedCrimeTypes = map[language.Tag]map[string]string{
+	language.Italian: {
+		"assault":                           "aggressione",
+		"murder":                            "omicidio",
+		"piracy":                            "pirateria",
+		"interdiction":                      "interdizione",
+		"illegal cargo":                     "carico illegale",
+		"fire in no fire zone":              "fuoco in zona di non belligeranza",
+		"fire in station":                   "fuoco nella stazione",
+		"reckless weapons discharge":        "uso sconsiderato delle armi",
+		"dumping dangerous":                 "scarico di merci pericolose",
+		"dumping near station":              "scarico vicino alla stazione",
+		"collided at speed in no fire zone": "collisione ad alta velocità in zona di non belligeranza",
+	},
+	language.German: {
+		"assault":                           "Angriff",
+		"murder":                            "Mord",
+		"piracy":                            "Piraterie",
+		"interdiction":                      "Abfangen",
+		"illegal cargo":                     "illegale Fracht",
+		"fire in no fire zone":              "Feuer in der Feuerverbotszone",
+		"fire in station":                   "Feuer in der Station",
+		"reckless weapons discharge":        "rücksichtsloser Waffeneinsatz",
+		"dumping dangerous":                 "gefährlicher Abwurf",
+		"dumping near station":              "Abwurf nahe der Station",
+		"collided at speed in no fire zone": "Kollision mit hoher Geschwindigkeit in der Feuerverbotszone",
+	},
+	language.French: {
+		"assault":                           "agression",
+		"murder":                            "meurtre",
+		"piracy":                            "piraterie",
+		"interdiction":                      "interdiction",
+		"illegal cargo":                     "cargaison illégale",
+		"fire in no fire zone":              "tir en zone de cessez-le-feu",
+		"fire in station":                   "tir dans la station",
+		"reckless weapons discharge":        "tir imprudent",
+		"dumping dangerous":                 "largage dangereux",
+		"dumping near station":              "largage près de la station",
+		"collided at speed in no fire zone": "collision à grande vitesse en zone de cessez-le-feu",
+	},
+	language.Spanish: {
+		"assault":                           "agresión",
+		"murder":                            "asesinato",
+		"piracy":                            "piratería",
+		"interdiction":                      "interdicción",
+		"illegal cargo":                     "carga ilegal",
+		"fire in no fire zone":              "disparo en zona de alto el fuego",
+		"fire in station":                   "disparo en la estación",
+		"reckless weapons discharge":        "disparo imprudente",
+		"dumping dangerous":                 "vertido peligroso",
+		"dumping near station":              "vertido cerca de la estación",
+		"collided at speed in no fire zone": "colisión a alta velocidad en zona de alto el fuego",
+	},
+}
+
+// parseLanguage returns the supported language matching the configured one, defaulting
+// to English when no language is configured
+func parseLanguage(s string) (language.Tag, error) {
+	if s == "" {
+		return language.English, nil
+	}
+
+	t, err := language.Parse(s)
+	if err != nil {
+		return language.English, fmt.Errorf("invalid language %q: %v", s, err)
+	}
+
+	_, i, confidence := languageMatcher.Match(t)
+	if confidence == language.No {
+		return language.English, fmt.Errorf("unsupported language %q, supported languages are: %v", s, supportedLanguages)
+	}
+
+	return supportedLanguages[i], nil
+}
+
+// localizedTemplates contains the translations of defaultTemplates
+var localizedTemplates = map[language.Tag]map[string]string{
+	language.Italian: {
+		hullDamageTemplate:        `Danni allo scafo {{if .Fighter}}del caccia{{else}}della nave{{end}}, integrità al {{percent .Health}}`,
+		diedTemplate:              `La tua nave è stata distrutta{{with .Rebuy}}, riacquisto {{credits .}} CR{{end}}{{with .Unclaimed}}, persi {{credits .}} CR di taglie non riscosse{{end}}`,
+		shieldsUpTemplate:         `Gli scudi sono di nuovo attivi`,
+		shieldsDownTemplate:       `Scudi abbassati!`,
+		killsTemplate:             "Ricompense totali: {{credits .Session.Bounties}} crediti\nPirati uccisi: {{.Session.Kills}}{{with .Session.Wanted}}\nRicercato da: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}\nMulte da pagare: {{credits .}} crediti{{end}}{{with .Session.CrewWages}}\nStipendi dell'equipaggio: {{credits .}} crediti{{end}}{{with .Session.CrewRanks}}\nPromozioni dell'equipaggio: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}",
+		combatBondsTemplate:       "Obbligazioni di combattimento: {{credits .Session.Bonds}} crediti\nNemici uccisi: {{.Session.BondKills}}{{range .Session.Sides}}\n- {{.Faction}}: {{credits .Reward}} crediti, uccisioni: {{.Kills}}{{end}}",
+		missionsCompletedTemplate: `Nessuna missione attiva rimasta, vai a prenderne di nuove!`,
+		startupTemplate: `ED-AFK-Notifier v{{.Version}} avviato
+
+Configurazione:
+- Servizio di notifica: {{.Service}}
+- Notifiche del caccia: {{if .FighterNotifs}}sì{{else}}no{{end}}
+- Notifiche degli scudi: {{if .ShieldsNotifs}}sì{{else}}no{{end}}
+- Notifiche delle uccisioni: {{if .KillsNotifs}}sì{{if .KillsSilentNotifs}} (modalità silenziosa){{else}} (tutte le uccisioni){{end}}{{else}}no{{end}}`,
+		quietSummaryTemplate: `Mentre eri via, {{len .Suppressed}} notifiche sono state trattenute:
+{{range .Suppressed}}
+{{.Time}} {{.Text}}{{end}}{{if or .Session.Wanted .Session.Fines}}
+{{with .Session.Wanted}}
+Ricercato da: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}
+Multe da pagare: {{credits .}} crediti{{end}}{{end}}{{if .Session.BondKills}}
+
+Obbligazioni di combattimento: {{credits .Session.Bonds}} crediti, nemici uccisi: {{.Session.BondKills}}{{end}}{{if or .Session.CrewWages .Session.CrewRanks}}
+{{with .Session.CrewWages}}
+Stipendi dell'equipaggio: {{credits .}} crediti{{end}}{{with .Session.CrewRanks}}
+Promozioni dell'equipaggio: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}{{end}}`,
+		lateDeliveryTemplate:       `Consegnato in ritardo, è successo alle {{.Time}}: {{.Text}}`,
+		repeatedTemplate:           `(ripetuto {{.Count}} volte)`,
+		interdictedTemplate:        `Interdetto da {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}{{if not .IsThargoid}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}}){{end}}, {{if .Submitted}}hai ceduto{{else}}non sei riuscito a sfuggire{{end}}`,
+		escapeInterdictionTemplate: `Sfuggito all'interdizione di {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}`,
+		interdictionTemplate:       `{{if .Success}}Interdizione riuscita{{else}}Interdizione fallita{{end}}: {{if .IsPlayer}}CMDR {{end}}{{or .Interdicted_Localised .Interdicted}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}})`,
+		threatTemplate:             `Contatto pericoloso: {{or .Ship_Localised .Ship}}{{with or .PilotName_Localised .PilotName}} pilotato da {{.}}{{end}}{{with .PilotRank}} ({{combatRank .}}){{end}}{{with .LegalStatus}}, {{.}}{{end}}{{if .HullHealth}}, scudi {{percent100 .ShieldHealth}}, scafo {{percent100 .HullHealth}}{{end}}`,
+		underAttackTemplate:        `{{if eq .Target "Fighter"}}Il tuo caccia è sotto attacco{{else if eq .Target "Mothership"}}La tua nave è sotto attacco{{else}}Sei sotto attacco{{end}}`,
+		scannedTemplate:            `La tua nave è stata scansionata{{with .ScanType}} (scansione {{.}}){{end}}`,
+		cargoLowTemplate:           `Il carico è sceso a {{.Count}} t, i pirati potrebbero smettere di attaccare`,
+		emptyCargoTemplate:         `Sei partito{{with .StationName}} da {{.}}{{end}} con la stiva vuota, i pirati non attaccheranno`,
+		heatWarningTemplate:        `La nave si sta surriscaldando!`,
+		heatDamageTemplate:         `Danni da calore! I moduli si stanno danneggiando`,
+		heatCriticalTemplate:       `Danni da calore da {{.Seconds}} secondi, i moduli stanno cedendo!`,
+		systemsShutdownTemplate:    `I sistemi della nave sono stati spenti!`,
+		rebootRepairTemplate:       `Riavvio/riparazione completato: {{if .Modules}}{{range $i, $m := .Modules}}{{if $i}}, {{end}}{{$m}}{{end}} di nuovo operativi{{else}}nessun modulo riparato{{end}}`,
+		commitCrimeTemplate:        `Crimine commesso: {{crimeType .CrimeType}}{{with or .Victim_Localised .Victim}} contro {{.}}{{end}}{{if .Bounty}}, taglia di {{credits .Bounty}} crediti, sei ricercato da {{.Faction}}{{else if .Fine}}, multa di {{credits .Fine}} crediti{{with .Faction}} da {{.}}{{end}}{{end}}`,
+		crimeVictimTemplate:        `{{or .Offender_Localised .Offender}} ha commesso {{crimeType .CrimeType}} contro di te{{if .Bounty}}, taglia di {{credits .Bounty}} crediti{{end}}`,
+		wantedClearedTemplate:      `Non sei più ricercato{{with .Faction}} da {{.}}{{end}}`,
+		crewInactiveTemplate:       `Nessun membro dell'equipaggio è attivo con un hangar per caccia installato, il caccia non può essere lanciato`,
+		crewJoinedTemplate:         `CMDR {{.Crew}} si è unito al tuo equipaggio`,
+		crewRoleTemplate:           `CMDR {{.Crew}} ora ha il ruolo {{.Role}}`,
+		rebuyTemplate:              `Crediti insufficienti per il riacquisto della tua {{or .ShipName .Ship}}: {{credits .Credits}} CR disponibili, il riacquisto costa {{credits .Rebuy}} CR`,
+		unclaimedTemplate:          "Taglie non riscosse: {{credits .Unclaimed}} CR, oltre {{credits .Threshold}} CR, è ora di incassarle{{range .Factions}}\n- {{.Faction}}: {{credits .Amount}} CR{{end}}",
+		readinessTemplate: `Controllo pre-volo{{with .StationName}} in partenza da {{.}}{{end}}:{{with index .Checks "fighter"}}
+{{.}} Hangar per caccia{{end}}{{with index .Checks "crew"}}
+{{.}} Equipaggio{{with $.Crew}}: {{.}} attivo{{end}}{{end}}{{with index .Checks "cargo"}}
+{{.}} Carico esca: {{if ge $.Cargo 0}}{{$.Cargo}} t{{else}}sconosciuto{{end}}{{if $.EmptyHold}}, i pirati non attaccheranno{{end}}{{end}}{{with index .Checks "missions"}}
+{{.}} Missioni di massacro: {{$.Massacres}}{{end}}{{with index .Checks "shields"}}
+{{.}} Generatore di scudi{{end}}`,
+	},
+	language.German: {
+		hullDamageTemplate:        `Hüllenschaden am {{if .Fighter}}Jäger{{else}}Schiff{{end}} erkannt, Integrität bei {{percent .Health}}`,
+		diedTemplate:              `Dein Schiff wurde zerstört{{with .Rebuy}}, Rückkauf {{credits .}} CR{{end}}{{with .Unclaimed}}, {{credits .}} CR an nicht eingelösten Kopfgeldern verloren{{end}}`,
+		shieldsUpTemplate:         `Die Schilde sind wieder aktiv`,
+		shieldsDownTemplate:       `Die Schilde sind ausgefallen!`,
+		killsTemplate:             "Gesamtbelohnung: {{credits .Session.Bounties}} Credits\nZerstörte Piraten: {{.Session.Kills}}{{with .Session.Wanted}}\nGesucht von: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}\nOffene Geldstrafen: {{credits .}} Credits{{end}}{{with .Session.CrewWages}}\nCrew-Gehälter: {{credits .}} Credits{{end}}{{with .Session.CrewRanks}}\nCrew-Beförderungen: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}",
+		combatBondsTemplate:       "Kampfanleihen: {{credits .Session.Bonds}} Credits\nZerstörte Gegner: {{.Session.BondKills}}{{range .Session.Sides}}\n- {{.Faction}}: {{credits .Reward}} Credits, Abschüsse: {{.Kills}}{{end}}",
+		missionsCompletedTemplate: `Keine aktiven Missionen mehr, hol dir neue!`,
+		startupTemplate: `ED-AFK-Notifier v{{.Version}} gestartet
+
+Konfiguration:
+- Benachrichtigungsdienst: {{.Service}}
+- Jäger-Benachrichtigungen: {{if .FighterNotifs}}ja{{else}}nein{{end}}
+- Schild-Benachrichtigungen: {{if .ShieldsNotifs}}ja{{else}}nein{{end}}
+- Abschuss-Benachrichtigungen: {{if .KillsNotifs}}ja{{if .KillsSilentNotifs}} (stiller Modus){{else}} (alle Abschüsse){{end}}{{else}}nein{{end}}`,
+		quietSummaryTemplate: `Während du weg warst, wurden {{len .Suppressed}} Benachrichtigungen zurückgehalten:
+{{range .Suppressed}}
+{{.Time}} {{.Text}}{{end}}{{if or .Session.Wanted .Session.Fines}}
+{{with .Session.Wanted}}
+Gesucht von: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}
+Offene Geldstrafen: {{credits .}} Credits{{end}}{{end}}{{if .Session.BondKills}}
+
+Kampfanleihen: {{credits .Session.Bonds}} Credits, zerstörte Gegner: {{.Session.BondKills}}{{end}}{{if or .Session.CrewWages .Session.CrewRanks}}
+{{with .Session.CrewWages}}
+Crew-Gehälter: {{credits .}} Credits{{end}}{{with .Session.CrewRanks}}
+Crew-Beförderungen: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}{{end}}`,
+		lateDeliveryTemplate:       `Verspätet zugestellt, das geschah um {{.Time}}: {{.Text}}`,
+		repeatedTemplate:           `({{.Count}} Mal wiederholt)`,
+		interdictedTemplate:        `Abgefangen von {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}{{if not .IsThargoid}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}}){{end}}, {{if .Submitted}}du hast dich ergeben{{else}}die Flucht ist gescheitert{{end}}`,
+		escapeInterdictionTemplate: `Dem Abfangen durch {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}} entkommen`,
+		interdictionTemplate:       `{{if .Success}}Abfangen erfolgreich{{else}}Abfangen fehlgeschlagen{{end}}: {{if .IsPlayer}}CMDR {{end}}{{or .Interdicted_Localised .Interdicted}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}})`,
+		threatTemplate:             `Gefährlicher Kontakt: {{or .Ship_Localised .Ship}}{{with or .PilotName_Localised .PilotName}}, Pilot {{.}}{{end}}{{with .PilotRank}} ({{combatRank .}}){{end}}{{with .LegalStatus}}, {{.}}{{end}}{{if .HullHealth}}, Schilde {{percent100 .ShieldHealth}}, Hülle {{percent100 .HullHealth}}{{end}}`,
+		underAttackTemplate:        `{{if eq .Target "Fighter"}}Dein Jäger wird angegriffen{{else if eq .Target "Mothership"}}Dein Schiff wird angegriffen{{else}}Du wirst angegriffen{{end}}`,
+		scannedTemplate:            `Dein Schiff wurde gescannt{{with .ScanType}} ({{.}}-Scan){{end}}`,
+		cargoLowTemplate:           `Die Fracht ist auf {{.Count}} t gesunken, Piraten greifen eventuell nicht mehr an`,
+		emptyCargoTemplate:         `Du hast{{with .StationName}} von {{.}}{{end}} mit leerem Frachtraum abgedockt, Piraten werden nicht angreifen`,
+		heatWarningTemplate:        `Das Schiff überhitzt!`,
+		heatDamageTemplate:         `Hitzeschaden! Module werden beschädigt`,
+		heatCriticalTemplate:       `Hitzeschaden seit {{.Seconds}} Sekunden, die Module fallen aus!`,
+		systemsShutdownTemplate:    `Die Schiffssysteme wurden abgeschaltet!`,
+		rebootRepairTemplate:       `Neustart/Reparatur abgeschlossen: {{if .Modules}}{{range $i, $m := .Modules}}{{if $i}}, {{end}}{{$m}}{{end}} wieder online{{else}}kein Modul repariert{{end}}`,
+		commitCrimeTemplate:        `Verbrechen begangen: {{crimeType .CrimeType}}{{with or .Victim_Localised .Victim}} gegen {{.}}{{end}}{{if .Bounty}}, Kopfgeld von {{credits .Bounty}} Credits, du wirst von {{.Faction}} gesucht{{else if .Fine}}, Bußgeld von {{credits .Fine}} Credits{{with .Faction}} von {{.}}{{end}}{{end}}`,
+		crimeVictimTemplate:        `{{or .Offender_Localised .Offender}} hat {{crimeType .CrimeType}} gegen dich begangen{{if .Bounty}}, Kopfgeld von {{credits .Bounty}} Credits{{end}}`,
+		wantedClearedTemplate:      `Nicht mehr gesucht{{with .Faction}} von {{.}}{{end}}`,
+		crewInactiveTemplate:       `Kein Crewmitglied ist aktiv, obwohl ein Jägerhangar eingebaut ist, der Jäger kann nicht starten`,
+		crewJoinedTemplate:         `CMDR {{.Crew}} ist deiner Crew beigetreten`,
+		crewRoleTemplate:           `CMDR {{.Crew}} hat jetzt die Rolle {{.Role}}`,
+		rebuyTemplate:              `Nicht genug Credits für den Rückkauf deines Schiffs {{or .ShipName .Ship}}: {{credits .Credits}} CR verfügbar, der Rückkauf kostet {{credits .Rebuy}} CR`,
+		unclaimedTemplate:          "Nicht eingelöste Kopfgelder: {{credits .Unclaimed}} CR, über {{credits .Threshold}} CR, Zeit sie einzulösen{{range .Factions}}\n- {{.Faction}}: {{credits .Amount}} CR{{end}}",
+		readinessTemplate: `Vorflugcheck{{with .StationName}} beim Abflug von {{.}}{{end}}:{{with index .Checks "fighter"}}
+{{.}} Jägerhangar{{end}}{{with index .Checks "crew"}}
+{{.}} Crew{{with $.Crew}}: {{.}} aktiv{{end}}{{end}}{{with index .Checks "cargo"}}
+{{.}} Köderfracht: {{if ge $.Cargo 0}}{{$.Cargo}} t{{else}}unbekannt{{end}}{{if $.EmptyHold}}, Piraten werden nicht angreifen{{end}}{{end}}{{with index .Checks "missions"}}
+{{.}} Massakermissionen: {{$.Massacres}}{{end}}{{with index .Checks "shields"}}
+{{.}} Schildgenerator{{end}}`,
+	},
+	language.French: {
+		hullDamageTemplate:        `Dégâts à la coque {{if .Fighter}}du chasseur{{else}}du vaisseau{{end}}, intégrité à {{percent .Health}}`,
+		diedTemplate:              `Votre vaisseau a été détruit{{with .Rebuy}}, rachat de {{credits .}} CR{{end}}{{with .Unclaimed}}, {{credits .}} CR de primes non réclamées perdues{{end}}`,
+		shieldsUpTemplate:         `Les boucliers sont de nouveau actifs`,
+		shieldsDownTemplate:       `Boucliers hors service !`,
+		killsTemplate:             "Récompenses totales : {{credits .Session.Bounties}} crédits\nPirates éliminés : {{.Session.Kills}}{{with .Session.Wanted}}\nRecherché par : {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}\nAmendes impayées : {{credits .}} crédits{{end}}{{with .Session.CrewWages}}\nSalaires de l'équipage : {{credits .}} crédits{{end}}{{with .Session.CrewRanks}}\nPromotions de l'équipage : {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}",
+		combatBondsTemplate:       "Obligations de combat : {{credits .Session.Bonds}} crédits\nEnnemis éliminés : {{.Session.BondKills}}{{range .Session.Sides}}\n- {{.Faction}} : {{credits .Reward}} crédits, éliminations : {{.Kills}}{{end}}",
+		missionsCompletedTemplate: `Plus aucune mission active, allez en chercher de nouvelles !`,
+		startupTemplate: `ED-AFK-Notifier v{{.Version}} démarré
+
+Configuration :
+- Service de notification : {{.Service}}
+- Notifications du chasseur : {{if .FighterNotifs}}oui{{else}}non{{end}}
+- Notifications des boucliers : {{if .ShieldsNotifs}}oui{{else}}non{{end}}
+- Notifications des éliminations : {{if .KillsNotifs}}oui{{if .KillsSilentNotifs}} (mode silencieux){{else}} (toutes les éliminations){{end}}{{else}}non{{end}}`,
+		quietSummaryTemplate: `Pendant votre absence, {{len .Suppressed}} notifications ont été retenues :
+{{range .Suppressed}}
+{{.Time}} {{.Text}}{{end}}{{if or .Session.Wanted .Session.Fines}}
+{{with .Session.Wanted}}
+Recherché par : {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}
+Amendes impayées : {{credits .}} crédits{{end}}{{end}}{{if .Session.BondKills}}
+
+Obligations de combat : {{credits .Session.Bonds}} crédits, ennemis éliminés : {{.Session.BondKills}}{{end}}{{if or .Session.CrewWages .Session.CrewRanks}}
+{{with .Session.CrewWages}}
+Salaires de l'équipage : {{credits .}} crédits{{end}}{{with .Session.CrewRanks}}
+Promotions de l'équipage : {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}{{end}}`,
+		lateDeliveryTemplate:       `Livré en retard, cela s'est produit à {{.Time}} : {{.Text}}`,
+		repeatedTemplate:           `(répété {{.Count}} fois)`,
+		interdictedTemplate:        `Interdiction par {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}{{if not .IsThargoid}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}}){{end}}, {{if .Submitted}}vous vous êtes soumis{{else}}vous n'avez pas pu vous échapper{{end}}`,
+		escapeInterdictionTemplate: `Interdiction de {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}} évitée`,
+		interdictionTemplate:       `{{if .Success}}Interdiction réussie{{else}}Interdiction échouée{{end}} : {{if .IsPlayer}}CMDR {{end}}{{or .Interdicted_Localised .Interdicted}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}})`,
+		threatTemplate:             `Contact dangereux : {{or .Ship_Localised .Ship}}{{with or .PilotName_Localised .PilotName}} piloté par {{.}}{{end}}{{with .PilotRank}} ({{combatRank .}}){{end}}{{with .LegalStatus}}, {{.}}{{end}}{{if .HullHealth}}, boucliers {{percent100 .ShieldHealth}}, coque {{percent100 .HullHealth}}{{end}}`,
+		underAttackTemplate:        `{{if eq .Target "Fighter"}}Votre chasseur est attaqué{{else if eq .Target "Mothership"}}Votre vaisseau est attaqué{{else}}Vous êtes attaqué{{end}}`,
+		scannedTemplate:            `Votre vaisseau a été scanné{{with .ScanType}} (scan {{.}}){{end}}`,
+		cargoLowTemplate:           `La cargaison est descendue à {{.Count}} t, les pirates risquent de ne plus attaquer`,
+		emptyCargoTemplate:         `Vous avez décollé{{with .StationName}} de {{.}}{{end}} avec une soute vide, les pirates n'attaqueront pas`,
+		heatWarningTemplate:        `Le vaisseau surchauffe !`,
+		heatDamageTemplate:         `Dégâts thermiques ! Les modules sont endommagés`,
+		heatCriticalTemplate:       `Dégâts thermiques depuis {{.Seconds}} secondes, les modules lâchent !`,
+		systemsShutdownTemplate:    `Les systèmes du vaisseau ont été coupés !`,
+		rebootRepairTemplate:       `Redémarrage/réparation terminé : {{if .Modules}}{{range $i, $m := .Modules}}{{if $i}}, {{end}}{{$m}}{{end}} de nouveau en ligne{{else}}aucun module réparé{{end}}`,
+		commitCrimeTemplate:        `Crime commis : {{crimeType .CrimeType}}{{with or .Victim_Localised .Victim}} contre {{.}}{{end}}{{if .Bounty}}, prime de {{credits .Bounty}} crédits, vous êtes recherché par {{.Faction}}{{else if .Fine}}, amende de {{credits .Fine}} crédits{{with .Faction}} de {{.}}{{end}}{{end}}`,
+		crimeVictimTemplate:        `{{or .Offender_Localised .Offender}} a commis {{crimeType .CrimeType}} contre vous{{if .Bounty}}, prime de {{credits .Bounty}} crédits{{end}}`,
+		wantedClearedTemplate:      `Vous n'êtes plus recherché{{with .Faction}} par {{.}}{{end}}`,
+		crewInactiveTemplate:       `Aucun membre d'équipage n'est actif alors qu'un hangar à chasseurs est installé, le chasseur ne peut pas être lancé`,
+		crewJoinedTemplate:         `CMDR {{.Crew}} a rejoint votre équipage`,
+		crewRoleTemplate:           `CMDR {{.Crew}} a maintenant le rôle {{.Role}}`,
+		rebuyTemplate:              `Pas assez de crédits pour le rachat de votre {{or .ShipName .Ship}} : {{credits .Credits}} CR disponibles, le rachat coûte {{credits .Rebuy}} CR`,
+		unclaimedTemplate:          "Primes non réclamées : {{credits .Unclaimed}} CR, plus de {{credits .Threshold}} CR, il est temps de les encaisser{{range .Factions}}\n- {{.Faction}} : {{credits .Amount}} CR{{end}}",
+		readinessTemplate: `Vérification avant vol{{with .StationName}} en quittant {{.}}{{end}} :{{with index .Checks "fighter"}}
+{{.}} Hangar à chasseurs{{end}}{{with index .Checks "crew"}}
+{{.}} Équipage{{with $.Crew}} : {{.}} actif{{end}}{{end}}{{with index .Checks "cargo"}}
+{{.}} Cargaison appât : {{if ge $.Cargo 0}}{{$.Cargo}} t{{else}}inconnue{{end}}{{if $.EmptyHold}}, les pirates n'attaqueront pas{{end}}{{end}}{{with index .Checks "missions"}}
+{{.}} Missions de massacre : {{$.Massacres}}{{end}}{{with index .Checks "shields"}}
+{{.}} Générateur de boucliers{{end}}`,
+	},
+	language.Spanish: {
+		hullDamageTemplate:        `Daños en el casco {{if .Fighter}}del caza{{else}}de la nave{{end}}, integridad al {{percent .Health}}`,
+		diedTemplate:              `Tu nave ha sido destruida{{with .Rebuy}}, recompra de {{credits .}} CR{{end}}{{with .Unclaimed}}, {{credits .}} CR en recompensas sin cobrar perdidas{{end}}`,
+		shieldsUpTemplate:         `Los escudos vuelven a estar activos`,
+		shieldsDownTemplate:       `¡Escudos caídos!`,
+		killsTemplate:             "Recompensas totales: {{credits .Session.Bounties}} créditos\nPiratas eliminados: {{.Session.Kills}}{{with .Session.Wanted}}\nBuscado por: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}\nMultas pendientes: {{credits .}} créditos{{end}}{{with .Session.CrewWages}}\nSalarios de la tripulación: {{credits .}} créditos{{end}}{{with .Session.CrewRanks}}\nAscensos de la tripulación: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}",
+		combatBondsTemplate:       "Bonos de combate: {{credits .Session.Bonds}} créditos\nEnemigos eliminados: {{.Session.BondKills}}{{range .Session.Sides}}\n- {{.Faction}}: {{credits .Reward}} créditos, bajas: {{.Kills}}{{end}}",
+		missionsCompletedTemplate: `No quedan misiones activas, ¡ve a por nuevas!`,
+		startupTemplate: `ED-AFK-Notifier v{{.Version}} iniciado
+
+Configuración:
+- Servicio de notificaciones: {{.Service}}
+- Notificaciones del caza: {{if .FighterNotifs}}sí{{else}}no{{end}}
+- Notificaciones de escudos: {{if .ShieldsNotifs}}sí{{else}}no{{end}}
+- Notificaciones de bajas: {{if .KillsNotifs}}sí{{if .KillsSilentNotifs}} (modo silencioso){{else}} (todas las bajas){{end}}{{else}}no{{end}}`,
+		quietSummaryTemplate: `Mientras estabas fuera, se retuvieron {{len .Suppressed}} notificaciones:
+{{range .Suppressed}}
+{{.Time}} {{.Text}}{{end}}{{if or .Session.Wanted .Session.Fines}}
+{{with .Session.Wanted}}
+Buscado por: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}
+Multas pendientes: {{credits .}} créditos{{end}}{{end}}{{if .Session.BondKills}}
+
+Bonos de combate: {{credits .Session.Bonds}} créditos, enemigos eliminados: {{.Session.BondKills}}{{end}}{{if or .Session.CrewWages .Session.CrewRanks}}
+{{with .Session.CrewWages}}
+Salarios de la tripulación: {{credits .}} créditos{{end}}{{with .Session.CrewRanks}}
+Ascensos de la tripulación: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}{{end}}`,
+		lateDeliveryTemplate:       `Entregado con retraso, ocurrió a las {{.Time}}: {{.Text}}`,
+		repeatedTemplate:           `(repetido {{.Count}} veces)`,
+		interdictedTemplate:        `Interdictado por {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}{{if not .IsThargoid}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}}){{end}}, {{if .Submitted}}te has rendido{{else}}no has podido escapar{{end}}`,
+		escapeInterdictionTemplate: `Has escapado de la interdicción de {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}`,
+		interdictionTemplate:       `{{if .Success}}Interdicción lograda{{else}}Interdicción fallida{{end}}: {{if .IsPlayer}}CMDR {{end}}{{or .Interdicted_Localised .Interdicted}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}})`,
+		threatTemplate:             `Contacto peligroso: {{or .Ship_Localised .Ship}}{{with or .PilotName_Localised .PilotName}} pilotado por {{.}}{{end}}{{with .PilotRank}} ({{combatRank .}}){{end}}{{with .LegalStatus}}, {{.}}{{end}}{{if .HullHealth}}, escudos {{percent100 .ShieldHealth}}, casco {{percent100 .HullHealth}}{{end}}`,
+		underAttackTemplate:        `{{if eq .Target "Fighter"}}Tu caza está siendo atacado{{else if eq .Target "Mothership"}}Tu nave está siendo atacada{{else}}Estás siendo atacado{{end}}`,
+		scannedTemplate:            `Tu nave ha sido escaneada{{with .ScanType}} (escaneo {{.}}){{end}}`,
+		cargoLowTemplate:           `La carga ha bajado a {{.Count}} t, los piratas podrían dejar de atacar`,
+		emptyCargoTemplate:         `Has despegado{{with .StationName}} de {{.}}{{end}} con la bodega vacía, los piratas no atacarán`,
+		heatWarningTemplate:        `¡La nave se está sobrecalentando!`,
+		heatDamageTemplate:         `¡Daños por calor! Los módulos se están dañando`,
+		heatCriticalTemplate:       `Daños por calor durante {{.Seconds}} segundos, ¡los módulos están fallando!`,
+		systemsShutdownTemplate:    `¡Los sistemas de la nave se han apagado!`,
+		rebootRepairTemplate:       `Reinicio/reparación completado: {{if .Modules}}{{range $i, $m := .Modules}}{{if $i}}, {{end}}{{$m}}{{end}} de nuevo en línea{{else}}ningún módulo reparado{{end}}`,
+		commitCrimeTemplate:        `Delito cometido: {{crimeType .CrimeType}}{{with or .Victim_Localised .Victim}} contra {{.}}{{end}}{{if .Bounty}}, recompensa de {{credits .Bounty}} créditos, eres buscado por {{.Faction}}{{else if .Fine}}, multa de {{credits .Fine}} créditos{{with .Faction}} de {{.}}{{end}}{{end}}`,
+		crimeVictimTemplate:        `{{or .Offender_Localised .Offender}} ha cometido {{crimeType .CrimeType}} contra ti{{if .Bounty}}, recompensa de {{credits .Bounty}} créditos{{end}}`,
+		wantedClearedTemplate:      `Ya no eres buscado{{with .Faction}} por {{.}}{{end}}`,
+		crewInactiveTemplate:       `Ningún tripulante está activo con un hangar de cazas instalado, el caza no puede despegar`,
+		crewJoinedTemplate:         `CMDR {{.Crew}} se ha unido a tu tripulación`,
+		crewRoleTemplate:           `CMDR {{.Crew}} ahora tiene el rol {{.Role}}`,
+		rebuyTemplate:              `No tienes créditos suficientes para la recompra de tu {{or .ShipName .Ship}}: {{credits .Credits}} CR disponibles, la recompra cuesta {{credits .Rebuy}} CR`,
+		unclaimedTemplate:          "Recompensas sin cobrar: {{credits .Unclaimed}} CR, más de {{credits .Threshold}} CR, es hora de cobrarlas{{range .Factions}}\n- {{.Faction}}: {{credits .Amount}} CR{{end}}",
+		readinessTemplate: `Comprobación previa al vuelo{{with .StationName}} al salir de {{.}}{{end}}:{{with index .Checks "fighter"}}
+{{.}} Hangar de cazas{{end}}{{with index .Checks "crew"}}
+{{.}} Tripulación{{with $.Crew}}: {{.}} activo{{end}}{{end}}{{with index .Checks "cargo"}}
+{{.}} Carga cebo: {{if ge $.Cargo 0}}{{$.Cargo}} t{{else}}desconocida{{end}}{{if $.EmptyHold}}, los piratas no atacarán{{end}}{{end}}{{with index .Checks "missions"}}
+{{.}} Misiones de masacre: {{$.Massacres}}{{end}}{{with index .Checks "shields"}}
+{{.}} Generador de escudos{{end}}`,
+	},
+}
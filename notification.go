@@ -0,0 +1,157 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/bots"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// Categories of notifications, used by the schedule to decide how to deliver them
+const (
+	categoryCritical     = "critical" // ship destroyed, hull integrity below the critical threshold, player interdiction or contact, persisting heat damage, systems shutdown or bounty
+	categoryHull         = "hull"
+	categoryShields      = "shields"
+	categoryKills        = "kills"
+	categoryMissions     = "missions"
+	categoryInterdiction = "interdiction" // interdictions by NPCs, those by players are critical
+	categoryThreat       = "threat"       // dangerous contacts, attacks and scans, player contacts are critical
+	categoryCargo        = "cargo"        // cargo used as bait for the pirates
+	categoryHeat         = "heat"         // overheating, persisting heat damage is critical
+	categoryModules      = "modules"      // reboot/repair outcome, systems shutdown is critical
+	categoryCrime        = "crime"        // fines and crimes, those making the commander wanted are critical
+	categoryCrew         = "crew"         // NPC crew not active while a fighter bay is fitted, multicrew commanders
+	categoryReadiness    = "readiness"    // checklist sent when undocking
+	categoryRules        = "rules"        // notifications of the user defined rules, unless critical
+	categorySummary      = "summary"      // summaries generated by the notifier itself
+)
+
+var categories = []string{categoryCritical, categoryHull, categoryShields, categoryKills, categoryMissions, categoryInterdiction, categoryThreat, categoryCargo, categoryHeat, categoryModules, categoryCrime, categoryCrew, categoryReadiness, categoryRules, categorySummary}
+
+func isCategory(c string) bool {
+	for _, category := range categories {
+		if c == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+type notification struct {
+	Source    string // journal directory of the event, notifications of different sources are throttled independently
+	Backend   string // dispatcher queue delivering the notification, every queue when empty
+	Event     string // journal event generating the notification, e.g. "ShieldState"
+	Category  string
+	Text      string
+	Silent    bool
+	Timestamp time.Time // time of the event generating the notification
+}
+
+func (e *Notifier) notify(n notification, skipNotify bool) error {
+	if skipNotify {
+		return nil
+	}
+
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now()
+	}
+
+	n.Source = e.journalPath
+	n.Text = e.withPrefix(n.Text)
+
+	if e.throttle != nil {
+		ok, repeated := e.throttle.allow(n, time.Now())
+		if !ok {
+			return nil
+		}
+		if repeated > 0 {
+			n.Text += " " + e.repeatedText(repeated)
+		}
+	}
+
+	if e.schedule != nil {
+		switch e.schedule.policy(n, time.Now()) {
+		case policySuppress:
+			e.schedule.suppress(n)
+			return nil
+		case policySilent:
+			n.Silent = true
+		}
+	}
+
+	return e.send(n)
+}
+
+// withPrefix prefixes the text with the commander name, when notifications of more
+// commanders are sent to the same chat
+func (e *Notifier) withPrefix(text string) string {
+	if e.prefix && e.commander != "" {
+		return fmt.Sprintf("[CMDR %s] %s", e.commander, text)
+	}
+
+	return text
+}
+
+// notifyTemplate renders the named template for the journal event and sends the result
+func (e *Notifier) notifyTemplate(name, category string, ev journal.Event, skipNotify bool) error {
+	return e.notifyTemplateWith(name, category, ev, nil, skipNotify)
+}
+
+// notifyTemplateWith is like notifyTemplate, adding the values to the data of the template
+func (e *Notifier) notifyTemplateWith(name, category string, ev journal.Event, values map[string]interface{}, skipNotify bool) error {
+	if skipNotify {
+		return nil
+	}
+
+	msg, err := e.renderWith(name, ev, values)
+	if err != nil {
+		return err
+	}
+
+	return e.notify(notification{Event: ev.EventName(), Category: category, Text: msg, Timestamp: ev.Time()}, skipNotify)
+}
+
+// send delivers the notification through the dispatcher or, when there is no dispatcher,
+// synchronously with the bot
+func (e *Notifier) send(n notification) error {
+	if e.dispatcher != nil {
+		n.Backend = e.backend
+		return e.dispatcher.enqueue(n)
+	}
+
+	if err := bots.SendMessage(e.bot, bots.Message{Text: n.Text, Silent: n.Silent}); err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+
+	return nil
+}
+
+// lateText returns the text of a notification delivered long after its event, reporting
+// when the event happened
+func (e *Notifier) lateText(n notification) string {
+	msg, err := e.execute(lateDeliveryTemplate, map[string]interface{}{
+		"Time": n.Timestamp.Local().Format("15:04"),
+		"Text": n.Text,
+	})
+	if err != nil {
+		log.Errorf("Cannot build the late delivery notification: %v", err)
+		return n.Text
+	}
+
+	return msg
+}
+
+// repeatedText returns the text reporting how many times a notification has been repeated
+// while throttled
+func (e *Notifier) repeatedText(count int) string {
+	msg, err := e.execute(repeatedTemplate, map[string]interface{}{"Count": count})
+	if err != nil {
+		log.Errorf("Cannot build the repeated notifications text: %v", err)
+		return ""
+	}
+
+	return msg
+}
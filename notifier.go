@@ -1,173 +1,463 @@
 package notifier
 
 import (
-	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
-	"github.com/hpcloud/tail"
 	log "github.com/sirupsen/logrus"
 	"github.com/tommyblue/ED-AFK-Notifier/bots"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/language"
 )
 
 type Notifier struct {
-	bot                 bots.Bot
-	journalFile         string
-	journalChanged      chan struct{}
-	cfg                 *Cfg
-	totalPiratesReward  int
+	bot            bots.Bot
+	backend        string           // dispatcher queue of the notifications of the journal
+	journalPath    string           // directory of the journal files
+	commander      string           // commander of the journal, from the config or the journal
+	fixedCommander bool             // the commander is configured, the journal doesn't change it
+	prefix         bool             // prefix the notifications with the commander name
+	sources        []*Notifier      // notifiers of every configured journal, including this one
+	position       journal.Position // position of the next journal line to process
+	continued      bool             // the session continues in the next journal file
+	cfg            *Cfg
+	lang           language.Tag // language of the notifications
+	templates      map[string]*template.Template
+	schedule       *schedule
+	throttle       *throttle
+	threat         *threat
+	rules          []*rule
+	dispatcher     *dispatcher
+	startTime      time.Time
+
+	// mu guards the session state below, updated by readJournal and read by the summary of
+	// the schedule from its own goroutine
+	mu                  sync.Mutex
+	totalPiratesReward  int64
 	killedPirates       int
 	activeMissions      int
-	loggedMissions      map[int]bool
-	totalMissionsReward int
+	loggedMissions      map[int64]bool
+	massacres           map[int64]bool // active massacre missions
+	totalMissionsReward int64
+	totalBonds          int64                // credits earned with combat bonds
+	bondKills           int                  // kills in conflict zones
+	bondSides           map[string]sideStats // combat bonds by awarding faction
+	contacts            map[string]bool      // dangerous contacts already reported, by pilot and ship
+	wanted              map[string]int64     // bounties on the commander, by faction
+	fines               map[string]int64     // unpaid fines of the commander, by faction
+	cargo               int                  // tons of cargo of the ship, -1 when unknown
+	cargoLow            bool                 // the cargo below the minimum has been reported
+	heat                heatState
+	loadout             *journal.Loadout // last loadout of the ship, nil when unknown
+	credits             int64            // credits of the commander when the game was loaded, -1 when unknown
+	rebuyChecked        bool             // the credits have been compared with the rebuy of the ship
+	unclaimed           map[string]int64 // bounties earned in the session and not redeemed yet, by faction
+	unclaimedThreshold  int64            // highest threshold of the unclaimed bounties notified
+	crew                crewState
+	crewWages           int64    // credits paid to the NPC crew in the session
+	crewRanks           []string // promotions of the NPC crew in the session, e.g. "Kara Voss (Expert)"
 }
 
 type Cfg struct {
 	NotificationService string // Which notification service to use: "telegram" or "gotify"
+	Language            string // Language of the notifications and bot replies, e.g. "en" or "it"
 
 	// Telegram settings
 	TelegramToken     string
 	TelegramChannelId int64
+	TelegramRate      float64 // messages per second, 0 for no limit
+	TelegramBurst     int     // messages that can be sent at once before applying the rate
 
 	// Gotify settings
 	GotifyURL      string
 	GotifyToken    string
 	GotifyTitle    string
-	GotifyPriority int // Priority of the Gotify notification
+	GotifyPriority int     // Priority of the Gotify notification
+	GotifyRate     float64 // messages per second, 0 for no limit
+	GotifyBurst    int     // messages that can be sent at once before applying the rate
 
 	// Journal settings
-	JournalPath       string
+	JournalPath       string       // empty or "auto" to detect the journal directory
+	Journals          []JournalCfg // journals of more commanders, JournalPath is ignored when set
 	FighterNotifs     bool
-	ShieldsNotifs     bool // notify about shields state
-	KillsNotifs       bool // notify about killed pirates
-	KillsSilentNotifs bool // reduce number of notifications for killed pirates, sending a notification every 10 kills
+	ShieldsNotifs     bool    // notify about shields state
+	KillsNotifs       bool    // notify about killed pirates
+	KillsSilentNotifs bool    // reduce number of notifications for killed pirates, sending a notification every 10 kills
+	ConflictZones     bool    // conflict-zone mode: combat bonds are tracked and notified like the killed pirates
+	CriticalHull      float64 // ship hull integrity (0-1) at or below which hull damage is critical
+
+	// Outbound queue settings
+	QueueSize    int           // notifications queued per backend before dropping new ones
+	QueueWorkers int           // goroutines sending the notifications of each backend
+	SendTimeout  time.Duration // timeout of a single send
+	Coalesce     time.Duration // notifications queued within this window are merged in one message
+
+	// Outbox of the notifications that couldn't be delivered, nil to disable it
+	Outbox *OutboxCfg
+
+	// Cooldowns and duplicate suppression of the notifications
+	Throttle ThrottleCfg
+
+	// Dangerous contacts, attacks and scans
+	Threat ThreatCfg
+
+	// Alerts about the cargo used as bait for the pirates
+	Cargo CargoCfg
+
+	// Throttling and escalation of the heat notifications
+	Heat HeatCfg
+
+	// Checks run when undocking
+	Readiness ReadinessCfg
+
+	// Alerts about the unclaimed bounties
+	Bounties BountiesCfg
+
+	// Schedule of the notifications, e.g. to only receive critical ones at night
+	Schedule ScheduleCfg
+
+	// User defined notifications, matching arbitrary journal events
+	Rules []RuleCfg
+
+	// Templates overriding the default notification texts, keyed by template name (e.g. "hull_damage")
+	Templates map[string]string
 }
 
-// New returns a Notifier with provided configuration
-func New(cfg *Cfg) (*Notifier, error) {
-	var (
-		bot bots.Bot
-		err error
-	)
+// JournalCfg is a journal directory read by the notifier, e.g. the one of another commander
+type JournalCfg struct {
+	Path              string // empty or "auto" to detect the journal directory
+	Commander         string // name in the notifications, taken from the journal when empty
+	TelegramChannelId int64  // chat of the notifications of the journal, the default one when 0
+	GotifyToken       string // application of the notifications of the journal, the default one when empty
+}
 
-	switch cfg.NotificationService {
-	case "telegram":
-		bot, err = bots.NewTelegram(cfg.TelegramToken, cfg.TelegramChannelId)
-		if err != nil {
-			return nil, fmt.Errorf("cannot setup the Telegram bot: %v", err)
-		}
-	case "gotify":
-		bot, err = bots.NewGotify(cfg.GotifyURL, cfg.GotifyToken, cfg.GotifyTitle, cfg.GotifyPriority)
-		if err != nil {
-			return nil, fmt.Errorf("cannot setup the Gotify service: %v", err)
-		}
+// journalSources returns the configured journals, or the journal of JournalPath when there
+// are none
+func (c *Cfg) journalSources() []JournalCfg {
+	if len(c.Journals) == 0 {
+		return []JournalCfg{{Path: c.JournalPath}}
+	}
+
+	return c.Journals
+}
+
+// botCfg returns the configuration of the bot sending the notifications of the journal, or
+// nil when they are sent by the default bot
+func (j JournalCfg) botCfg(cfg *Cfg) *Cfg {
+	c := *cfg
+	switch {
+	case cfg.NotificationService == "telegram" && j.TelegramChannelId != 0 && j.TelegramChannelId != cfg.TelegramChannelId:
+		c.TelegramChannelId = j.TelegramChannelId
+	case cfg.NotificationService == "gotify" && j.GotifyToken != "" && j.GotifyToken != cfg.GotifyToken:
+		c.GotifyToken = j.GotifyToken
 	default:
-		return nil, fmt.Errorf("unknown notification service: %s", cfg.NotificationService)
+		return nil
 	}
 
-	j, err := journalFile(cfg.JournalPath)
+	return &c
+}
+
+// New returns a Notifier with provided configuration. With more journals, the returned
+// Notifier reads all of them, each with its own session counters.
+func New(cfg *Cfg) (*Notifier, error) {
+	lang, err := parseLanguage(cfg.Language)
 	if err != nil {
 		return nil, err
 	}
-	log.Infoln("Found most recent journal file:", j)
 
-	e := &Notifier{
-		bot:            bot,
-		journalFile:    filepath.Join(cfg.JournalPath, j),
-		journalChanged: make(chan struct{}),
-		cfg:            cfg,
+	templates, err := parseTemplates(cfg.Templates, lang)
+	if err != nil {
+		return nil, err
 	}
 
-	e.initNotifier()
+	thr, err := newThrottle(cfg.Throttle)
+	if err != nil {
+		return nil, err
+	}
 
-	e.watchJournal()
+	threats, err := newThreat(cfg.Threat)
+	if err != nil {
+		return nil, err
+	}
 
-	return e, nil
-}
+	if err := cfg.Readiness.validate(); err != nil {
+		return nil, err
+	}
 
-func (e *Notifier) watchJournal() {
-	go func() {
-		for range time.Tick(30 * time.Second) {
+	bot, err := newBot(cfg, lang)
+	if err != nil {
+		return nil, err
+	}
 
-			oldJournal := e.journalFile
-			j, err := journalFile(e.cfg.JournalPath)
-			if err != nil {
-				continue
-			}
+	var ob *outbox
+	if cfg.Outbox != nil {
+		if ob, err = newOutbox(*cfg.Outbox); err != nil {
+			return nil, err
+		}
+	}
+
+	backends := map[string]bots.Bot{cfg.NotificationService: bot}
+	paths := make(map[string]bool)
+	var sources []*Notifier
+	for i, src := range cfg.journalSources() {
+		journalPath, err := ResolveJournalPath(src.Path)
+		if err != nil {
+			return nil, err
+		}
+		if paths[journalPath] {
+			return nil, fmt.Errorf("journal %s is configured more than once", journalPath)
+		}
+		paths[journalPath] = true
+		if len(cfg.Journals) == 0 {
+			cfg.JournalPath = journalPath
+		}
+
+		j, err := journal.LatestFile(journalPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Infoln("Found most recent journal file:", j)
+
+		// Schedule and rules keep the state of the journal, e.g. the suppressed notifications
+		sched, err := newSchedule(cfg.Schedule)
+		if err != nil {
+			return nil, err
+		}
 
-			if oldJournal != filepath.Join(e.cfg.JournalPath, j) {
-				log.Infoln("Found new journal file:", j)
-				e.journalFile = filepath.Join(e.cfg.JournalPath, j)
+		rules, err := newRules(cfg.Rules, lang)
+		if err != nil {
+			return nil, err
+		}
 
-				e.initNotifier()
+		e := &Notifier{
+			bot:            bot,
+			backend:        cfg.NotificationService,
+			journalPath:    journalPath,
+			commander:      src.Commander,
+			fixedCommander: src.Commander != "",
+			position:       journal.Position{File: j},
+			cargo:          -1,
+			credits:        -1,
+			cfg:            cfg,
+			lang:           lang,
+			templates:      templates,
+			schedule:       sched,
+			throttle:       thr,
+			threat:         threats,
+			rules:          rules,
+			startTime:      time.Now(),
+		}
 
-				e.journalChanged <- struct{}{}
+		if botCfg := src.botCfg(cfg); botCfg != nil {
+			if e.bot, err = newBot(botCfg, lang); err != nil {
+				return nil, err
 			}
+			e.backend = fmt.Sprintf("%s:%d", cfg.NotificationService, i+1)
+			backends[e.backend] = e.bot
 		}
-	}()
+
+		sources = append(sources, e)
+	}
+
+	d := newDispatcher(cfg, backends, ob, sources[0].lateText)
+	for _, e := range sources {
+		// Notifications of more commanders in the same chat are told apart by the prefix
+		e.prefix = len(sources) > 1 && e.backend == cfg.NotificationService
+		e.dispatcher = d
+		e.initNotifier()
+	}
+
+	e := sources[0]
+	e.sources = sources
+
+	return e, nil
 }
 
-type eventType string
-
-var (
-	bountyEventType            eventType = "Bounty"
-	missionsEventType          eventType = "Missions"
-	missionAcceptedEventType   eventType = "MissionAccepted"
-	missionRedirectedEventType eventType = "MissionRedirected"
-	missionCompletedEventType  eventType = "MissionCompleted"
-	missionAbandonedEventType  eventType = "MissionAbandoned"
-	hullDamageEventType        eventType = "HullDamage"
-	diedEventType              eventType = "Died"
-	shieldStateEventType       eventType = "ShieldState"
-)
+// rateLimit returns the rate (messages per second) and burst of the backend
+func (c *Cfg) rateLimit(backend string) (float64, int) {
+	// Queues of the journals with their own chat are named "<service>:<n>"
+	service, _, _ := strings.Cut(backend, ":")
+	switch service {
+	case "telegram":
+		return c.TelegramRate, c.TelegramBurst
+	case "gotify":
+		return c.GotifyRate, c.GotifyBurst
+	default:
+		return 0, 0
+	}
+}
+
+func newBot(cfg *Cfg, lang language.Tag) (bots.Bot, error) {
+	switch cfg.NotificationService {
+	case "telegram":
+		bot, err := bots.NewTelegram(cfg.TelegramToken, cfg.TelegramChannelId, lang)
+		if err != nil {
+			return nil, fmt.Errorf("cannot setup the Telegram bot: %v", err)
+		}
+		return bot, nil
+	case "gotify":
+		bot, err := bots.NewGotify(cfg.GotifyURL, cfg.GotifyToken, cfg.GotifyTitle, cfg.GotifyPriority)
+		if err != nil {
+			return nil, fmt.Errorf("cannot setup the Gotify service: %v", err)
+		}
+		return bot, nil
+	default:
+		return nil, fmt.Errorf("unknown notification service: %s", cfg.NotificationService)
+	}
+}
 
 func (e *Notifier) initCounters() {
 	e.totalPiratesReward = 0
 	e.killedPirates = 0
 	e.activeMissions = 0
-	e.loggedMissions = make(map[int]bool)
+	e.loggedMissions = make(map[int64]bool)
+	e.massacres = make(map[int64]bool)
 	e.totalMissionsReward = 0
+	e.totalBonds = 0
+	e.bondKills = 0
+	e.bondSides = make(map[string]sideStats)
+	e.contacts = make(map[string]bool)
+	e.wanted = make(map[string]int64)
+	e.fines = make(map[string]int64)
+	e.crewWages = 0
+	e.crewRanks = nil
+	e.unclaimed = make(map[string]int64)
+	e.unclaimedThreshold = 0
 }
 
+// initNotifier initializes the counters with the events of the session, up to the current
+// position. The session starts from the first part of the current journal file, when the
+// game has split it in more files. When the position is at the beginning of the file, the
+// whole file is read and the position moves to its end. The cargo is read from Cargo.json
+// when the session doesn't report it.
 func (e *Notifier) initNotifier() {
 	e.initCounters()
+	defer e.loadCargo()
+
+	files := e.sessionFiles()
+	if len(files) > 1 {
+		log.Infof("Session continued in %d journal files, reading them from %s", len(files), files[0])
+	}
+	inSession := make(map[string]bool)
+	for _, f := range files {
+		inSession[f] = true
+	}
 
-	file, err := os.Open(e.journalFile)
+	r, err := journal.OpenReader(e.journalPath, journal.Position{File: files[0]})
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
+	defer r.Close()
+
+	readAll := e.position.Offset == 0
 
-	scanner := bufio.NewScanner(file)
 	var lastMissionsTs time.Time
-	for scanner.Scan() {
-		var j journalEvent
+	for {
+		if pos := r.Position(); !readAll && pos.File == e.position.File && pos.Offset >= e.position.Offset {
+			break
+		}
 
-		line := scanner.Text()
-		if err := json.Unmarshal([]byte(line), &j); err != nil {
-			log.Infof("Cannot unmarshal %s", line)
+		entry, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var decodeErr *journal.DecodeError
+		if errors.As(err, &decodeErr) {
+			log.Infof("Cannot unmarshal %s", decodeErr.Line)
 
 			continue
 		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// The game has moved to a new file in the meanwhile, Start reads it from the beginning
+		if !inSession[entry.Position.File] {
+			e.position = entry.Position
+			return
+		}
+
+		// The login is over without a hint about the crew, checked when reading the new events
+		e.crewLoggedIn(entry.Event)
+
+		switch j := entry.Event.(type) {
+
+		case *journal.Commander:
+			e.setCommander(j)
+
+		case *journal.LoadGame:
+			e.setCommander(j)
+			e.loadGame(j)
+
+		case *journal.RedeemVoucher:
+			e.redeemVoucher(j)
+
+		case *journal.Died:
+			e.loseBounties()
+
+		case *journal.Continued:
+			e.continued = true
+
+		case *journal.Cargo:
+			if j.Vessel == "" || j.Vessel == "Ship" {
+				e.cargo = j.Count
+			}
+
+		case *journal.Loadout:
+			e.loadout = j
+
+		case *journal.CrewAssign:
+			e.crewAssign(j)
+
+		case *journal.NpcCrewPaidWage:
+			e.crewWages += j.Amount
+			e.crewPaid(j.NpcCrewName)
+
+		case *journal.NpcCrewRank:
+			e.crewRank(j)
+			e.crewPaid(j.NpcCrewName)
+
+		case *journal.CommitCrime:
+			e.commitCrime(j)
+
+		case *journal.PayBounties:
+			e.payBounties(j)
 
-		switch j.Event {
+		case *journal.PayFines:
+			e.payFines(j)
 
-		case bountyEventType:
-			e.totalPiratesReward += j.TotalPiratesReward
+		case *journal.Bounty:
+			e.totalPiratesReward += j.TotalReward
+			e.addBounty(j)
+			e.unclaimedThreshold = e.cfg.Bounties.threshold(e.unclaimedTotal())
 			e.killedPirates++
 
 			log.Debugf("Total reward: %d\n", e.totalPiratesReward)
 			log.Debugf("Killed pirates: %d\n", e.killedPirates)
 
-		case missionsEventType:
+		case *journal.FactionKillBond:
+			if !e.cfg.ConflictZones {
+				continue
+			}
+			e.addBond(j)
+
+			log.Debugf("Total combat bonds: %d\n", e.totalBonds)
+			log.Debugf("Enemies killed: %d\n", e.bondKills)
+
+		case *journal.Missions:
 			lastMissionsTs = j.Timestamp
 			e.activeMissions = 0
+			e.massacres = make(map[int64]bool)
 			for _, m := range j.Active {
 				if m.Expires != 0 {
 					e.activeMissions++
+					e.massacres[m.MissionID] = isMassacre(m.Name)
 				}
 			}
 
@@ -175,14 +465,15 @@ func (e *Notifier) initNotifier() {
 
 		// The following actions must be accepted only if their timestamp is newer the last
 		// "Missions" event or the missions count will be wrong.
-		case missionAcceptedEventType:
+		case *journal.MissionAccepted:
 			if j.Timestamp.After(lastMissionsTs) {
 				continue
 			}
 			e.activeMissions++
+			e.massacres[j.MissionID] = isMassacre(j.Name)
 			log.Debugf("Active missions: %d\n", e.activeMissions)
 
-		case missionRedirectedEventType:
+		case *journal.MissionRedirected:
 			if j.Timestamp.After(lastMissionsTs) {
 				continue
 			}
@@ -192,10 +483,11 @@ func (e *Notifier) initNotifier() {
 
 			e.activeMissions--
 			e.loggedMissions[j.MissionID] = true
+			delete(e.massacres, j.MissionID)
 
 			log.Debugf("Active missions: %d\n", e.activeMissions)
 
-		case missionCompletedEventType:
+		case *journal.MissionCompleted:
 			if j.Timestamp.After(lastMissionsTs) {
 				continue
 			}
@@ -205,25 +497,27 @@ func (e *Notifier) initNotifier() {
 
 			e.activeMissions--
 			delete(e.loggedMissions, j.MissionID)
+			delete(e.massacres, j.MissionID)
 
-			e.totalMissionsReward += j.MissionReward
+			e.totalMissionsReward += j.Reward
 
 			log.Debugf("Active missions: %d\n", e.activeMissions)
 			log.Debugf("Total missions reward: %d\n", e.totalMissionsReward)
 
-		case missionAbandonedEventType:
+		case *journal.MissionAbandoned:
 			if j.Timestamp.After(lastMissionsTs) {
 				continue
 			}
 			e.activeMissions--
 			delete(e.loggedMissions, j.MissionID)
+			delete(e.massacres, j.MissionID)
 
 			log.Debugf("Active missions: %d\n", e.activeMissions)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+	if readAll {
+		e.position = r.Position()
 	}
 }
 
@@ -231,79 +525,144 @@ func (e *Notifier) initNotifier() {
 func (e *Notifier) Start() {
 	e.bot.Start()
 
+	for _, src := range e.sources {
+		if src == e {
+			continue
+		}
+		src.watchSchedule()
+		go src.readJournal()
+	}
+
+	e.watchSchedule()
+	e.readJournal()
+}
+
+// readJournal follows the journal, handling its events
+func (e *Notifier) readJournal() {
+	r, err := journal.OpenReader(e.journalPath, e.position)
+	if err != nil {
+		log.Fatalf("cannot read the journal: %v\n", err)
+	}
+	defer r.Close()
+
+	startTime := time.Now()
+
+	// The game of the session has been read by initNotifier, its loadout is checked here as
+	// the handler of Loadout runs only for the new events
+	e.mu.Lock()
+	if err := e.checkRebuy(false); err != nil {
+		log.Infoln("[ERROR]", err)
+	}
+	if e.loadout != nil {
+		if err := e.checkCrew(e.loadout, false); err != nil {
+			log.Infoln("[ERROR]", err)
+		}
+	}
+	e.mu.Unlock()
+
+	events := map[string]eventFn{
+		journal.HullDamageEvent:           hullDamageEvent,
+		journal.DiedEvent:                 diedEvent,
+		journal.ShieldStateEvent:          shieldStateEvent,
+		journal.FactionKillBondEvent:      factionKillBondEvent,
+		journal.BountyEvent:               bountyEvent,
+		journal.MissionAcceptedEvent:      missionAcceptedEvent,
+		journal.MissionCompletedEvent:     missionCompletedEvent,
+		journal.MissionRedirectedEvent:    missionRedirectedEvent,
+		journal.MissionAbandonedEvent:     missionAbandonedEvent,
+		journal.MissionsEvent:             missionsInitEvent,
+		journal.ContinuedEvent:            continuedEvent,
+		journal.CommanderEvent:            commanderEvent,
+		journal.LoadGameEvent:             loadGameEvent,
+		journal.InterdictedEvent:          interdictedEvent,
+		journal.EscapeInterdictionEvent:   escapeInterdictionEvent,
+		journal.InterdictionEvent:         interdictionEvent,
+		journal.ShipTargetedEvent:         shipTargetedEvent,
+		journal.UnderAttackEvent:          underAttackEvent,
+		journal.ScannedEvent:              scannedEvent,
+		journal.CargoEvent:                cargoEvent,
+		journal.EjectCargoEvent:           ejectCargoEvent,
+		journal.CargoTransferEvent:        cargoTransferEvent,
+		journal.UndockedEvent:             undockedEvent,
+		journal.HeatWarningEvent:          heatWarningEvent,
+		journal.HeatDamageEvent:           heatDamageEvent,
+		journal.SystemsShutdownEvent:      systemsShutdownEvent,
+		journal.RebootRepairEvent:         rebootRepairEvent,
+		journal.CommitCrimeEvent:          commitCrimeEvent,
+		journal.CrimeVictimEvent:          crimeVictimEvent,
+		journal.PayBountiesEvent:          payBountiesEvent,
+		journal.PayFinesEvent:             payFinesEvent,
+		journal.LoadoutEvent:              loadoutEvent,
+		journal.CrewAssignEvent:           crewAssignEvent,
+		journal.CrewMemberJoinsEvent:      crewMemberJoinsEvent,
+		journal.CrewMemberRoleChangeEvent: crewMemberRoleChangeEvent,
+		journal.NpcCrewRankEvent:          npcCrewRankEvent,
+		journal.RedeemVoucherEvent:        redeemVoucherEvent,
+		journal.NpcCrewPaidWageEvent:      npcCrewPaidWageEvent,
+	}
+
+	log.Infoln("Reading journal", e.journalPath)
 	for {
-		log.Infoln("Reading journal...")
-		t, err := tail.TailFile(e.journalFile, tail.Config{Follow: true, Poll: true})
+		entry, err := r.Follow(context.Background())
+		var decodeErr *journal.DecodeError
+		if errors.As(err, &decodeErr) {
+			log.Infof("Cannot unmarshal %s", decodeErr.Line)
+			e.position = r.Position()
+			continue
+		}
 		if err != nil {
-			log.Fatalf("cannot tail the log file: %v\n", err)
+			log.Fatalf("cannot read the journal: %v\n", err)
 		}
 
-		go func() {
-			<-e.journalChanged
-			log.Infoln("Journal changed, reloading...")
-			t.Stop()
-		}()
-
-		startTime := time.Now()
-
-		events := map[eventType]eventFn{
-			hullDamageEventType:        hullDamageEvent,
-			diedEventType:              diedEvent,
-			shieldStateEventType:       shieldStateEvent,
-			bountyEventType:            bountyEvent,
-			missionAcceptedEventType:   missionAcceptedEvent,
-			missionCompletedEventType:  missionCompletedEvent,
-			missionRedirectedEventType: missionRedirectedEvent,
-			missionAbandonedEventType:  missionAbandonedEvent,
-			missionsEventType:          missionsInitEvent,
+		e.mu.Lock()
+		if entry.Position.File != e.position.File {
+			e.journalRotated(entry)
 		}
+		e.position = r.Position()
 
-		for line := range t.Lines {
-			var j journalEvent
-			if err := json.Unmarshal([]byte(line.Text), &j); err != nil {
-				log.Infof("Cannot unmarshal %s", line.Text)
-			}
-
-			// Skip logs already in the journal befor this app has started
-			var skipNotify bool
-			if j.Timestamp.Before(startTime) {
-				skipNotify = true
-			}
+		ev := entry.Event
 
-			// log.Debugln(line.Text)
+		// Skip logs already in the journal befor this app has started
+		var skipNotify bool
+		if ev.Time().Before(startTime) {
+			skipNotify = true
+		}
 
-			if fn, ok := events[j.Event]; ok {
-				if err := fn(e, j, skipNotify); err != nil {
-					log.Infoln("[ERROR]", err)
-				}
+		if fn, ok := events[ev.EventName()]; ok {
+			if err := fn(e, ev, skipNotify); err != nil {
+				log.Infoln("[ERROR]", err)
 			}
 		}
+
+		if err := crewLoginEvent(e, ev, skipNotify); err != nil {
+			log.Infoln("[ERROR]", err)
+		}
+
+		e.applyRules(ev, skipNotify)
+		e.mu.Unlock()
 	}
 }
 
 // SendStartupNotification sends a notification with version and config info
 func (e *Notifier) SendStartupNotification(version string) {
-	msg := fmt.Sprintf("ED-AFK-Notifier v%s started\n\n", version)
-
-	// Add configuration information
-	msg += "Configuration:\n"
-	msg += fmt.Sprintf("- Notification service: %s\n", e.cfg.NotificationService)
-	msg += fmt.Sprintf("- Fighter notifications: %t\n", e.cfg.FighterNotifs)
-	msg += fmt.Sprintf("- Shield notifications: %t\n", e.cfg.ShieldsNotifs)
-	msg += fmt.Sprintf("- Kill notifications: %t", e.cfg.KillsNotifs)
-
-	if e.cfg.KillsNotifs {
-		if e.cfg.KillsSilentNotifs {
-			msg += " (silent mode)"
-		} else {
-			msg += " (all kills)"
-		}
+	msg, err := e.execute(startupTemplate, startupData(version, e.cfg))
+	if err != nil {
+		log.Errorf("Failed to build startup notification: %v", err)
+		return
 	}
 
-	// Try to send the notification
-	if err := e.bot.Send(msg); err != nil {
-		log.Errorf("Failed to send startup notification: %v", err)
-	} else {
-		log.Infoln("Startup notification sent successfully")
+	// Try to send the notification, also to the chats of the other journals
+	sent := make(map[string]bool)
+	for _, src := range append([]*Notifier{e}, e.sources...) {
+		if sent[src.backend] {
+			continue
+		}
+		sent[src.backend] = true
+
+		if err := src.bot.Send(msg); err != nil {
+			log.Errorf("Failed to send startup notification: %v", err)
+		} else {
+			log.Infoln("Startup notification sent successfully")
+		}
 	}
 }
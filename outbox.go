@@ -0,0 +1,331 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/bots"
+)
+
+// Retry settings of the outbox
+const (
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+	outboxJitter      = 0.2 // the backoff is randomly changed by up to ±20%
+
+	// Notifications delivered this long after their event are marked as late
+	lateThreshold = time.Minute
+)
+
+// OutboxCfg configures the outbox storing the notifications that couldn't be delivered
+type OutboxCfg struct {
+	Path     string                   // file storing the undelivered notifications (default: in the user cache dir)
+	Expiry   map[string]time.Duration // per category, undelivered notifications older than this are dropped
+	Collapse []string                 // categories for which only the most recent undelivered notification is kept
+}
+
+// defaultOutboxExpiry apply to the categories without a configured expiry, so that stale
+// notifications aren't retried forever. A configured expiry of 0 disables them.
+var defaultOutboxExpiry = map[string]time.Duration{
+	categoryCritical:     24 * time.Hour,
+	categoryHull:         2 * time.Hour,
+	categoryShields:      time.Hour,
+	categoryKills:        6 * time.Hour,
+	categoryMissions:     24 * time.Hour,
+	categoryInterdiction: 2 * time.Hour,
+	categoryThreat:       time.Hour,
+	categoryCargo:        2 * time.Hour,
+	categoryHeat:         time.Hour,
+	categoryModules:      2 * time.Hour,
+	categoryCrime:        6 * time.Hour,
+	categoryCrew:         6 * time.Hour,
+	categoryReadiness:    time.Hour,
+	categoryRules:        6 * time.Hour,
+	categorySummary:      12 * time.Hour,
+}
+
+type outboxEntry struct {
+	Backend      string
+	Notification notification
+	Attempts     int
+	NextAttempt  time.Time
+
+	id uint64 // identifies the entry while it's retried without the lock, not persisted
+}
+
+// outbox persists on disk the notifications that couldn't be delivered, retrying them with
+// exponential backoff
+type outbox struct {
+	path     string
+	expiry   map[string]time.Duration
+	collapse map[string]bool
+
+	mu      sync.Mutex
+	entries []outboxEntry
+	lastID  uint64
+}
+
+// DefaultOutboxPath returns the default path of the outbox file
+func DefaultOutboxPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "ed-afk-notifier", "outbox.json")
+}
+
+func newOutbox(cfg OutboxCfg) (*outbox, error) {
+	o := &outbox{
+		path:     cfg.Path,
+		expiry:   make(map[string]time.Duration),
+		collapse: make(map[string]bool),
+	}
+	if o.path == "" {
+		o.path = DefaultOutboxPath()
+	}
+
+	for c, d := range defaultOutboxExpiry {
+		o.expiry[c] = d
+	}
+	for c, d := range cfg.Expiry {
+		if !isCategory(c) {
+			return nil, fmt.Errorf("unknown outbox expiry category %q", c)
+		}
+		o.expiry[c] = d
+	}
+	for _, c := range cfg.Collapse {
+		if !isCategory(c) {
+			return nil, fmt.Errorf("unknown outbox collapse category %q", c)
+		}
+		o.collapse[c] = true
+	}
+
+	if err := o.load(); err != nil {
+		return nil, fmt.Errorf("cannot load the outbox: %v", err)
+	}
+	if len(o.entries) > 0 {
+		log.Infof("Found %d undelivered notifications in the outbox", len(o.entries))
+	}
+
+	return o, nil
+}
+
+func (o *outbox) load() error {
+	b, err := os.ReadFile(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, &o.entries); err != nil {
+		return err
+	}
+	for i := range o.entries {
+		o.lastID++
+		o.entries[i].id = o.lastID
+	}
+
+	return nil
+}
+
+// save writes the entries to disk, must be called holding the lock
+func (o *outbox) save() {
+	b, err := json.Marshal(o.entries)
+	if err != nil {
+		log.Errorf("Cannot encode the outbox: %v", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o700); err != nil {
+		log.Errorf("Cannot create the outbox directory: %v", err)
+		return
+	}
+
+	// Write to a temporary file first, so that a crash never leaves a truncated outbox
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		log.Errorf("Cannot write the outbox: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		log.Errorf("Cannot write the outbox: %v", err)
+	}
+}
+
+// add stores a notification that couldn't be delivered by the backend, returning the id of
+// its entry. For collapsed categories, older undelivered notifications of the same category
+// and journal source are replaced.
+func (o *outbox) add(backend string, n notification, now time.Time) uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.collapse[n.Category] {
+		entries := o.entries[:0]
+		for _, e := range o.entries {
+			if e.Backend != backend || e.Notification.Category != n.Category || e.Notification.Source != n.Source {
+				entries = append(entries, e)
+			}
+		}
+		o.entries = entries
+	}
+
+	o.lastID++
+	o.entries = append(o.entries, outboxEntry{
+		Backend:      backend,
+		Notification: n,
+		Attempts:     1,
+		NextAttempt:  now.Add(backoff(1)),
+		id:           o.lastID,
+	})
+	o.save()
+
+	return o.lastID
+}
+
+// remove drops the entry with the given id, if it's still in the outbox
+func (o *outbox) remove(id uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, e := range o.entries {
+		if e.id == id {
+			o.entries = append(o.entries[:i], o.entries[i+1:]...)
+			o.save()
+			return
+		}
+	}
+}
+
+// removeWhenDelivered drops the entry when a send abandoned after the timeout succeeds
+// later, so that the notification isn't delivered twice
+func (o *outbox) removeWhenDelivered(id uint64, err error) {
+	var timeoutErr *sendTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		return
+	}
+
+	go func() {
+		if err := <-timeoutErr.result; err == nil {
+			log.Debugf("Abandoned send delivered late, removing it from the outbox")
+			o.remove(id)
+		}
+	}()
+}
+
+// retry tries to deliver the due notifications with the send function. After the first
+// failure of a backend, its other notifications are postponed to the next round, while
+// those rejected for good by the backend are dropped. The
+// notifications are sent without holding the lock, so that add is never blocked by a slow
+// backend, and the outbox is saved only when its entries change.
+func (o *outbox) retry(now time.Time, send func(backend string, n notification) error) {
+	due, changed := o.dueEntries(now)
+	if len(due) == 0 {
+		if changed {
+			o.mu.Lock()
+			o.save()
+			o.mu.Unlock()
+		}
+		return
+	}
+
+	failed := make(map[string]bool)
+	results := make(map[uint64]bool, len(due)) // by entry id, whether it has been delivered or dropped
+	for _, e := range due {
+		if failed[e.Backend] {
+			continue
+		}
+
+		err := send(e.Backend, e.Notification)
+		var permErr *bots.PermanentError
+		if errors.As(err, &permErr) {
+			log.Infof("Dropping notification rejected by %s: %v", e.Backend, err)
+			results[e.id] = true
+			continue
+		}
+		if err != nil {
+			log.Debugf("Retry %d of %s failed: %v", e.Attempts, e.Backend, err)
+			o.removeWhenDelivered(e.id, err)
+			failed[e.Backend] = true
+			results[e.id] = false
+			continue
+		}
+
+		log.Infof("Delivered notification from the outbox after %d attempts", e.Attempts+1)
+		results[e.id] = true
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Entries added or collapsed while sending are kept as they are
+	entries := o.entries[:0]
+	for _, e := range o.entries {
+		delivered, ok := results[e.id]
+		if ok && delivered {
+			continue
+		}
+		if ok {
+			e.Attempts++
+			e.NextAttempt = now.Add(backoff(e.Attempts))
+		}
+		entries = append(entries, e)
+	}
+
+	o.entries = entries
+	o.save()
+}
+
+// dueEntries drops the expired entries and returns a copy of those due for a new attempt,
+// reporting whether any entry has been dropped
+func (o *outbox) dueEntries(now time.Time) ([]outboxEntry, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var due []outboxEntry
+	changed := false
+	entries := o.entries[:0]
+	for _, e := range o.entries {
+		if expiry := o.expiry[e.Notification.Category]; expiry > 0 && !e.Notification.Timestamp.IsZero() && now.Sub(e.Notification.Timestamp) > expiry {
+			log.Infof("Dropping expired notification: %s", e.Notification.Text)
+			changed = true
+			continue
+		}
+
+		if !now.Before(e.NextAttempt) {
+			due = append(due, e)
+		}
+		entries = append(entries, e)
+	}
+	o.entries = entries
+
+	return due, changed
+}
+
+// backoff returns the delay before the next attempt, doubling at each attempt
+func backoff(attempts int) time.Duration {
+	d := outboxMaxBackoff
+	if attempts < 20 {
+		if exp := outboxBaseBackoff << uint(attempts-1); exp < outboxMaxBackoff {
+			d = exp
+		}
+	}
+
+	jitter := (rand.Float64()*2 - 1) * outboxJitter
+
+	return time.Duration(float64(d) * (1 + jitter))
+}
+
+// isLate returns whether the notification is delivered too long after its event
+func isLate(n notification, now time.Time) bool {
+	return !n.Timestamp.IsZero() && now.Sub(n.Timestamp) > lateThreshold
+}
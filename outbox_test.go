@@ -0,0 +1,203 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/bots"
+)
+
+func Test_outbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := newOutbox(OutboxCfg{
+		Path:     path,
+		Expiry:   map[string]time.Duration{categoryHull: time.Hour},
+		Collapse: []string{categoryKills},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	o.add("telegram", notification{Category: categoryKills, Text: "10 kills", Timestamp: now}, now)
+	o.add("telegram", notification{Category: categoryHull, Text: "hull 40%", Timestamp: now.Add(-2 * time.Hour)}, now)
+	o.add("telegram", notification{Category: categoryShields, Text: "shields down", Timestamp: now}, now)
+	o.add("telegram", notification{Category: categoryKills, Text: "20 kills", Timestamp: now}, now)
+
+	// The outbox is persisted and reloaded
+	o, err = newOutbox(OutboxCfg{Path: path, Expiry: map[string]time.Duration{categoryHull: time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.entries) != 3 {
+		t.Fatalf("want 3 entries, got: %d", len(o.entries))
+	}
+
+	// Nothing is due before the backoff
+	var sent []string
+	send := func(backend string, n notification) error {
+		sent = append(sent, n.Text)
+		return nil
+	}
+	o.retry(now, send)
+	if len(sent) != 0 {
+		t.Fatalf("sent %v before the backoff", sent)
+	}
+
+	// The expired hull notification is dropped and a failure postpones the other notifications
+	o.retry(now.Add(time.Minute), func(backend string, n notification) error {
+		sent = append(sent, n.Text)
+		return fmt.Errorf("network down")
+	})
+	if len(sent) != 1 || len(o.entries) != 2 {
+		t.Fatalf("want 1 attempt and 2 entries, got: %v, %d entries", sent, len(o.entries))
+	}
+	if o.entries[0].Attempts != 2 {
+		t.Fatalf("want 2 attempts, got: %d", o.entries[0].Attempts)
+	}
+
+	// The remaining notifications are delivered once the backend is back
+	sent = nil
+	o.retry(now.Add(time.Hour), send)
+	if len(o.entries) != 0 {
+		t.Fatalf("want no entries, got: %d", len(o.entries))
+	}
+	if want := []string{"shields down", "20 kills"}; fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Fatalf("want: %v, got: %v", want, sent)
+	}
+}
+
+func Test_backoff(t *testing.T) {
+	for attempts, base := range map[int]time.Duration{1: 5 * time.Second, 3: 20 * time.Second, 30: outboxMaxBackoff} {
+		d := backoff(attempts)
+		if d < time.Duration(float64(base)*(1-outboxJitter)) || d > time.Duration(float64(base)*(1+outboxJitter)) {
+			t.Fatalf("backoff(%d) = %s, want %s ±%.0f%%", attempts, d, base, outboxJitter*100)
+		}
+	}
+}
+
+func TestNotifier_lateText(t *testing.T) {
+	n := &Notifier{cfg: &Cfg{}}
+	ts := time.Date(2022, 10, 3, 3, 12, 0, 0, time.Local)
+
+	got := n.lateText(notification{Text: "Shields are down!", Timestamp: ts})
+	if want := "Delivered late, this happened at 03:12: Shields are down!"; got != want {
+		t.Fatalf("want: %s, got: %s", want, got)
+	}
+}
+
+func Test_outboxRetryWithoutLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := newOutbox(OutboxCfg{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	o.add("telegram", notification{Category: categoryShields, Text: "shields down", Timestamp: now}, now)
+
+	// Nothing changes before the backoff, so the outbox isn't saved again
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	o.retry(now, func(backend string, n notification) error { return nil })
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("outbox saved without changes: %v", err)
+	}
+
+	// A notification failing while another one is being retried is kept
+	o.retry(now.Add(time.Minute), func(backend string, n notification) error {
+		o.add("telegram", notification{Category: categoryHull, Text: "hull 40%", Timestamp: now}, now)
+		return nil
+	})
+	if len(o.entries) != 1 || o.entries[0].Notification.Text != "hull 40%" {
+		t.Fatalf("want the hull notification only, got: %v", o.entries)
+	}
+}
+
+func Test_outboxDefaultExpiry(t *testing.T) {
+	now := time.Now()
+	stale := notification{Category: categoryShields, Text: "shields down", Timestamp: now.Add(-2 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		expiry      map[string]time.Duration
+		wantEntries int
+	}{
+		{name: "default", wantEntries: 0},
+		{name: "configured", expiry: map[string]time.Duration{categoryShields: 3 * time.Hour}, wantEntries: 1},
+		{name: "disabled", expiry: map[string]time.Duration{categoryShields: 0}, wantEntries: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := newOutbox(OutboxCfg{Path: filepath.Join(t.TempDir(), "outbox.json"), Expiry: tt.expiry})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			o.add("telegram", stale, now)
+			o.retry(now, func(backend string, n notification) error { return nil })
+			if len(o.entries) != tt.wantEntries {
+				t.Fatalf("want: %d entries, got: %d", tt.wantEntries, len(o.entries))
+			}
+		})
+	}
+}
+
+func Test_outboxPermanentError(t *testing.T) {
+	o, err := newOutbox(OutboxCfg{Path: filepath.Join(t.TempDir(), "outbox.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	o.add("telegram", notification{Category: categoryShields, Text: "rejected", Timestamp: now}, now)
+	o.add("telegram", notification{Category: categoryHull, Text: "failed", Timestamp: now}, now)
+	o.add("telegram", notification{Category: categoryKills, Text: "postponed", Timestamp: now}, now)
+
+	// The rejected notification is dropped, the first failure postpones the others
+	var sent []string
+	o.retry(now.Add(time.Minute), func(backend string, n notification) error {
+		sent = append(sent, n.Text)
+		if n.Text == "rejected" {
+			return &bots.PermanentError{Err: fmt.Errorf("unexpected status code: 400")}
+		}
+		return fmt.Errorf("network down")
+	})
+	if want := []string{"rejected", "failed"}; fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Fatalf("want: %v, got: %v", want, sent)
+	}
+	if len(o.entries) != 2 || o.entries[0].Notification.Text != "failed" || o.entries[0].Attempts != 2 || o.entries[1].Attempts != 1 {
+		t.Fatalf("want the failed and postponed notifications, got: %v", o.entries)
+	}
+}
+
+func Test_outboxAbandonedSend(t *testing.T) {
+	o, err := newOutbox(OutboxCfg{Path: filepath.Join(t.TempDir(), "outbox.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	result := make(chan error, 1)
+	id := o.add("telegram", notification{Category: categoryShields, Text: "shields down", Timestamp: now}, now)
+	o.removeWhenDelivered(id, &sendTimeoutError{timeout: time.Second, result: result})
+
+	// The abandoned send succeeds late, so the notification isn't retried
+	result <- nil
+	for i := 0; i < 100; i++ {
+		o.mu.Lock()
+		n := len(o.entries)
+		o.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("abandoned send delivered late still in the outbox")
+}
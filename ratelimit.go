@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenBucket limits the rate of the messages sent to a backend
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64 // tokens per second, 0 for no limit
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // set when the backend asks to retry later
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// reserve takes a token, returning how long to wait before sending
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var wait time.Duration
+	if b.pausedUntil.After(now) {
+		wait = b.pausedUntil.Sub(now)
+	}
+
+	if b.rate <= 0 {
+		return wait
+	}
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+
+	// Negative tokens are reservations of future tokens
+	if b.tokens < 0 {
+		if w := time.Duration(-b.tokens / b.rate * float64(time.Second)); w > wait {
+			wait = w
+		}
+	}
+
+	return wait
+}
+
+// wait blocks until a message can be sent
+func (b *tokenBucket) wait() {
+	if d := b.reserve(time.Now()); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// pause stops the sends until the given time, e.g. following a retry_after response
+func (b *tokenBucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// coalesce merges the notifications in a single one, keeping the category only when
+// they all share it
+func coalesce(batch []notification) notification {
+	if len(batch) == 1 {
+		return batch[0]
+	}
+
+	merged := batch[0]
+	texts := []string{merged.Text}
+	for _, n := range batch[1:] {
+		texts = append(texts, n.Text)
+		if n.Category != merged.Category {
+			merged.Category = categorySummary
+		}
+		merged.Silent = merged.Silent && n.Silent
+		if n.Timestamp.Before(merged.Timestamp) {
+			merged.Timestamp = n.Timestamp
+		}
+	}
+	merged.Text = strings.Join(texts, "\n\n")
+
+	return merged
+}
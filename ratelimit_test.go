@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_tokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(1, 2, now)
+
+	// The burst is available immediately, then one token per second
+	for i, want := range []time.Duration{0, 0, time.Second, 2 * time.Second} {
+		if got := b.reserve(now); got != want {
+			t.Fatalf("reservation %d: want wait %s, got: %s", i, want, got)
+		}
+	}
+
+	// After the pause the reservations restart from the end of the pause
+	b = newTokenBucket(0, 0, now)
+	if got := b.reserve(now); got != 0 {
+		t.Fatalf("want no wait without rate, got: %s", got)
+	}
+	b.pause(now.Add(5 * time.Second))
+	if got := b.reserve(now.Add(time.Second)); got != 4*time.Second {
+		t.Fatalf("want 4s wait while paused, got: %s", got)
+	}
+}
+
+func Test_coalesce(t *testing.T) {
+	now := time.Now()
+	merged := coalesce([]notification{
+		{Category: categoryShields, Text: "Shields are down!", Silent: true, Timestamp: now},
+		{Category: categoryHull, Text: "Ship hull damage detected, integrity is 80%", Timestamp: now.Add(-time.Second)},
+	})
+
+	if want := "Shields are down!\n\nShip hull damage detected, integrity is 80%"; merged.Text != want {
+		t.Fatalf("want: %q, got: %q", want, merged.Text)
+	}
+	if merged.Category != categorySummary {
+		t.Fatalf("want category %s, got: %s", categorySummary, merged.Category)
+	}
+	if merged.Silent {
+		t.Fatalf("merged notification is silent, but not all notifications were")
+	}
+	if !merged.Timestamp.Equal(now.Add(-time.Second)) {
+		t.Fatalf("want the earliest timestamp, got: %s", merged.Timestamp)
+	}
+}
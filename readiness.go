@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// Most failed sessions are caused by something forgotten at the station: the readiness check
+// reports the state of the ship when undocking, in a single checklist.
+
+// Names of the readiness checks, also used in the config file
+const (
+	checkFighter  = "fighter"  // a fighter bay is fitted
+	checkCrew     = "crew"     // a crew member is active
+	checkCargo    = "cargo"    // bait cargo is aboard
+	checkMissions = "missions" // massacre missions are active
+	checkShields  = "shields"  // a shield generator is fitted
+)
+
+var readinessChecks = []string{checkFighter, checkCrew, checkCargo, checkMissions, checkShields}
+
+// Marks of the checklist
+const (
+	markPass    = "✅"
+	markWarn    = "⚠️"
+	markUnknown = "❔" // the journal hasn't reported the state yet, e.g. no crew role assigned in the session
+)
+
+// ReadinessCfg defines the checks run when undocking, none by default
+type ReadinessCfg struct {
+	Checks []string // names of the checks, e.g. "fighter" or "cargo"
+}
+
+func (c ReadinessCfg) validate() error {
+	for _, check := range c.Checks {
+		if !isReadinessCheck(check) {
+			return fmt.Errorf("unknown readiness check %q, valid checks are: %s", check, strings.Join(readinessChecks, ", "))
+		}
+	}
+
+	return nil
+}
+
+func isReadinessCheck(c string) bool {
+	for _, check := range readinessChecks {
+		if c == check {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMassacre returns whether the mission is a massacre mission, e.g. "Mission_MassacreWing"
+func isMassacre(name string) bool {
+	return strings.Contains(strings.ToLower(name), "massacre")
+}
+
+func (e *Notifier) massacreMissions() int {
+	var n int
+	for _, massacre := range e.massacres {
+		if massacre {
+			n++
+		}
+	}
+
+	return n
+}
+
+func mark(pass bool) string {
+	if pass {
+		return markPass
+	}
+
+	return markWarn
+}
+
+// readiness returns the mark of each configured check, keyed by check name
+func (e *Notifier) readiness() map[string]string {
+	checks := make(map[string]string)
+	for _, check := range e.cfg.Readiness.Checks {
+		m := markUnknown
+		switch check {
+		case checkFighter:
+			if e.loadout != nil {
+				m = mark(hasModule(e.loadout, "fighterbay"))
+			}
+		case checkShields:
+			if e.loadout != nil {
+				m = mark(hasModule(e.loadout, "shieldgenerator"))
+			}
+		case checkCrew:
+			if e.crew.known {
+				m = mark(e.crew.active != "")
+			}
+		case checkCargo:
+			if e.cargo >= 0 {
+				m = mark(e.cargo >= e.cfg.Cargo.bait())
+			}
+		case checkMissions:
+			m = mark(e.massacreMissions() > 0)
+		}
+		checks[check] = m
+	}
+
+	// The empty cargo hold warning is part of the checklist
+	if e.emptyHold() {
+		checks[checkCargo] = markWarn
+	}
+
+	return checks
+}
+
+func readinessEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if len(e.cfg.Readiness.Checks) == 0 {
+		return nil
+	}
+
+	values := map[string]interface{}{
+		"Checks":    e.readiness(),
+		"Crew":      e.crew.active,
+		"Cargo":     e.cargo,
+		"Massacres": e.massacreMissions(),
+		"EmptyHold": e.emptyHold(),
+	}
+
+	return e.notifyTemplateWith(readinessTemplate, categoryReadiness, ev, values, skipNotify)
+}
+
+// undockedEvent sends the checklist, reporting the empty cargo hold too, or only the empty
+// cargo hold warning when the readiness check is disabled
+func undockedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if len(e.cfg.Readiness.Checks) > 0 {
+		return readinessEvent(e, ev, skipNotify)
+	}
+
+	return emptyCargoEvent(e, ev, skipNotify)
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func TestReadinessCfg_validate(t *testing.T) {
+	if err := (ReadinessCfg{Checks: []string{"fighter", "crew", "cargo", "missions", "shields"}}).validate(); err != nil {
+		t.Fatalf("want: nil, got: %v", err)
+	}
+	if err := (ReadinessCfg{Checks: []string{"fuel"}}).validate(); err == nil {
+		t.Fatalf("want an error for an unknown check")
+	}
+}
+
+func Test_readinessEvent(t *testing.T) {
+	loadout := &journal.Loadout{
+		Ship: "type9_military",
+		Modules: []journal.Module{
+			{Slot: "Slot01_Size8", Item: "int_fighterbay_size7_class1"},
+			{Slot: "Slot02_Size8", Item: "int_shieldgenerator_size8_class5"},
+		},
+	}
+	noShields := &journal.Loadout{
+		Ship:    "type9_military",
+		Modules: []journal.Module{{Slot: "Slot01_Size8", Item: "int_fighterbay_size7_class1"}},
+	}
+
+	tests := []struct {
+		name      string
+		checks    []string
+		loadout   *journal.Loadout
+		crew      *journal.CrewAssign
+		cargo     int
+		massacres map[int64]bool
+		wantMsg   string
+	}{
+		{
+			name:   "disabled",
+			cargo:  -1,
+			checks: nil,
+		},
+		{
+			name:      "ready",
+			checks:    []string{"fighter", "crew", "cargo", "missions", "shields"},
+			loadout:   loadout,
+			crew:      &journal.CrewAssign{Name: "Kara Voss", Role: "Active"},
+			cargo:     2,
+			massacres: map[int64]bool{1: true, 2: true, 3: false},
+			wantMsg:   "Pre-flight check leaving Ford Terminal:\n✅ Fighter hangar\n✅ Crew: Kara Voss active\n✅ Bait cargo: 2 t\n✅ Massacre missions: 2\n✅ Shield generator",
+		},
+		{
+			name:      "warnings",
+			checks:    []string{"crew", "cargo", "missions", "shields"},
+			loadout:   noShields,
+			crew:      &journal.CrewAssign{Name: "Kara Voss", Role: "Idle"},
+			cargo:     0,
+			massacres: map[int64]bool{3: false},
+			wantMsg:   "Pre-flight check leaving Ford Terminal:\n⚠️ Crew\n⚠️ Bait cargo: 0 t\n⚠️ Massacre missions: 0\n⚠️ Shield generator",
+		},
+		{
+			name:    "unknown",
+			checks:  []string{"fighter", "crew", "cargo"},
+			cargo:   -1,
+			wantMsg: "Pre-flight check leaving Ford Terminal:\n❔ Fighter hangar\n❔ Crew\n❔ Bait cargo: unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n := &Notifier{cfg: &Cfg{Readiness: ReadinessCfg{Checks: tt.checks}}, bot: bot, cargo: tt.cargo, loadout: tt.loadout, massacres: tt.massacres}
+			if tt.crew != nil {
+				n.crewAssign(tt.crew)
+			}
+
+			if err := undockedEvent(n, &journal.Undocked{StationName: "Ford Terminal"}, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func Test_undockedEventEmptyHold(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []string
+		wantMsg string
+	}{
+		{
+			name:    "readiness disabled",
+			wantMsg: "You undocked from Ford Terminal with an empty cargo hold, pirates won't attack",
+		},
+		{
+			name:    "in the checklist",
+			checks:  []string{"cargo", "missions"},
+			wantMsg: "Pre-flight check leaving Ford Terminal:\n⚠️ Bait cargo: 0 t, pirates won't attack\n⚠️ Massacre missions: 0",
+		},
+		{
+			name:    "cargo check disabled",
+			checks:  []string{"missions"},
+			wantMsg: "Pre-flight check leaving Ford Terminal:\n⚠️ Bait cargo: 0 t, pirates won't attack\n⚠️ Massacre missions: 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &chanBot{sent: make(chan string, 10), release: make(chan struct{})}
+			close(bot.release)
+			cfg := &Cfg{Cargo: CargoCfg{Undock: true}, Readiness: ReadinessCfg{Checks: tt.checks}}
+			n := &Notifier{cfg: cfg, bot: bot, cargo: 0}
+
+			if err := undockedEvent(n, &journal.Undocked{StationName: "Ford Terminal"}, false); err != nil {
+				t.Fatal(err)
+			}
+			if len(bot.sent) != 1 {
+				t.Fatalf("want a single message, got: %d", len(bot.sent))
+			}
+			if got := <-bot.sent; got != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, got)
+			}
+		})
+	}
+}
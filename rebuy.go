@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// The game writes LoadGame, with the credits of the commander, and then the Loadout of the
+// ship, with its rebuy cost. A ship destroyed without the credits for the rebuy is lost.
+
+func (e *Notifier) loadGame(j *journal.LoadGame) {
+	e.credits = j.Credits
+	e.rebuyChecked = false
+	// The loadout of the new game follows, and the active crew member after it
+	e.loadout = nil
+	e.crew = crewState{login: true}
+}
+
+// checkRebuy notifies once per game when the credits aren't enough for the rebuy of the ship
+func (e *Notifier) checkRebuy(skipNotify bool) error {
+	if e.rebuyChecked || e.credits < 0 || e.loadout == nil {
+		return nil
+	}
+	e.rebuyChecked = true
+
+	if e.credits >= e.loadout.Rebuy {
+		return nil
+	}
+
+	values := map[string]interface{}{"Credits": e.credits}
+
+	return e.notifyTemplateWith(rebuyTemplate, categoryCritical, e.loadout, values, skipNotify)
+}
+
+func loadGameEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	e.setCommander(ev)
+	e.loadGame(ev.(*journal.LoadGame))
+
+	return nil
+}
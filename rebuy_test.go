@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_rebuyEvents(t *testing.T) {
+	loadout := &journal.Loadout{Ship: "type9_military", ShipName: "Bait Boat", HullValue: 72000000, ModulesValue: 180000000, Rebuy: 12600000}
+
+	tests := []struct {
+		name          string
+		fn            eventFn
+		ev            journal.Event
+		wantMsg       string
+		wantUnclaimed int64
+	}{
+		{name: "game loaded", fn: loadGameEvent, ev: &journal.LoadGame{Commander: "Jameson", Credits: 9000000}},
+		{
+			name:    "not enough credits",
+			fn:      loadoutEvent,
+			ev:      loadout,
+			wantMsg: "Not enough credits for the rebuy of your Bait Boat: 9,000,000 CR available, the rebuy costs 12,600,000 CR",
+		},
+		{name: "checked once per game", fn: loadoutEvent, ev: loadout},
+		{name: "bounty", fn: bountyEvent, ev: &journal.Bounty{TotalReward: 300000}, wantUnclaimed: 300000},
+		{name: "other voucher", fn: redeemVoucherEvent, ev: &journal.RedeemVoucher{Type: "trade", Amount: 100000}, wantUnclaimed: 300000},
+		{name: "redeemed", fn: redeemVoucherEvent, ev: &journal.RedeemVoucher{Type: "bounty", Amount: 200000}, wantUnclaimed: 100000},
+		{name: "another bounty", fn: bountyEvent, ev: &journal.Bounty{TotalReward: 50000}, wantUnclaimed: 150000},
+		{
+			name:    "died",
+			fn:      diedEvent,
+			ev:      &journal.Died{Header: journal.Header{Event: journal.DiedEvent}},
+			wantMsg: "Your ship has been destroyed, rebuy 12,600,000 CR, 150,000 CR of unclaimed bounties lost",
+		},
+		{name: "game loaded again", fn: loadGameEvent, ev: &journal.LoadGame{Commander: "Jameson", Credits: 20000000}},
+		{name: "enough credits", fn: loadoutEvent, ev: loadout},
+	}
+
+	n := &Notifier{cfg: &Cfg{}, credits: -1}
+	n.initCounters()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := tt.fn(n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+			if got := n.unclaimedTotal(); got != tt.wantUnclaimed {
+				t.Fatalf("wantUnclaimed: %d, got: %d", tt.wantUnclaimed, got)
+			}
+		})
+	}
+}
+
+func TestNotifier_checkRebuyAfterInit(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Journal.2024-03-09T201233.01.log"),
+		`{"timestamp":"2024-03-09T20:12:33Z","event":"Fileheader","part":1}`+"\n"+
+			`{"timestamp":"2024-03-09T20:12:40Z","event":"LoadGame","Commander":"Jameson","Credits":9000000}`+"\n"+
+			`{"timestamp":"2024-03-09T20:12:41Z","event":"Loadout","Ship":"type9_military","ShipName":"Bait Boat","Rebuy":12600000}`+"\n",
+		time.Now())
+
+	bot := &mockBot{}
+	n := &Notifier{cfg: &Cfg{}, bot: bot, journalPath: dir, position: journal.Position{File: "Journal.2024-03-09T201233.01.log"}}
+	n.initNotifier()
+
+	if err := n.checkRebuy(false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Not enough credits for the rebuy of your Bait Boat: 9,000,000 CR available, the rebuy costs 12,600,000 CR"; bot.sentMsg != want {
+		t.Fatalf("wantMsg: %s, got: %s", want, bot.sentMsg)
+	}
+
+	bot.sentMsg = ""
+	if err := n.checkRebuy(false); err != nil {
+		t.Fatal(err)
+	}
+	if bot.sentMsg != "" {
+		t.Fatalf("want no message, got: %s", bot.sentMsg)
+	}
+}
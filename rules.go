@@ -0,0 +1,222 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Severities of the rules
+const (
+	severityInfo     = "info"     // delivered without sound
+	severityWarning  = "warning"  // delivered normally
+	severityCritical = "critical" // delivered as a critical notification
+)
+
+// RuleCfg is a user defined notification, sent when a journal event matches its condition
+type RuleCfg struct {
+	Name     string        // name of the rule, used in logs and errors
+	Event    string        // journal event to match, e.g. "ShipTargeted" (default: every event)
+	When     string        // condition on the event fields and the session counters, e.g. `PilotRank == "Elite"`
+	Message  string        // template of the notification text
+	Severity string        // "info", "warning" (default) or "critical"
+	Cooldown time.Duration // minimum time between two notifications of the rule
+}
+
+type rule struct {
+	name     string
+	event    string
+	when     expression // nil when the rule has no condition
+	message  *template.Template
+	severity string
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	lastFired time.Time
+}
+
+// newRules compiles the conditions and the templates of the rules
+func newRules(cfgs []RuleCfg, lang language.Tag) ([]*rule, error) {
+	funcs := templateFuncs(message.NewPrinter(lang))
+
+	rules := make([]*rule, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		name := cfg.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		r := &rule{
+			name:     name,
+			event:    cfg.Event,
+			severity: strings.ToLower(cfg.Severity),
+			cooldown: cfg.Cooldown,
+		}
+
+		switch r.severity {
+		case "":
+			r.severity = severityWarning
+		case severityInfo, severityWarning, severityCritical:
+		default:
+			return nil, fmt.Errorf("rule %s: unknown severity %q, valid severities are: info, warning, critical", name, cfg.Severity)
+		}
+
+		if cfg.Cooldown < 0 {
+			return nil, fmt.Errorf("rule %s: invalid negative cooldown", name)
+		}
+
+		if cfg.When != "" {
+			when, err := compileExpression(cfg.When)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid condition: %v", name, err)
+			}
+			r.when = when
+		}
+
+		if cfg.Message == "" {
+			return nil, fmt.Errorf("rule %s: message is empty", name)
+		}
+		t, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(cfg.Message)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: cannot parse the message: %v", name, err)
+		}
+		if data := sampleRuleData(cfg.Event); data != nil {
+			if err := t.Execute(&strings.Builder{}, data); err != nil {
+				return nil, fmt.Errorf("rule %s: invalid message: %v", name, err)
+			}
+		}
+		r.message = t
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// sampleRuleData returns the data of a rule message for a sample event, like applyRules, or nil
+// when the fields of the event aren't known
+func sampleRuleData(event string) map[string]interface{} {
+	ev := journal.New(event)
+	if _, ok := ev.(*journal.Unknown); ok {
+		return nil
+	}
+
+	data, err := journal.Fields(ev)
+	if err != nil {
+		return nil
+	}
+	data["Session"] = sessionStats{}
+
+	return data
+}
+
+// match returns whether the raw journal event satisfies the rule. The environment contains
+// the event fields and the session counters as Session.
+func (r *rule) match(event string, env map[string]interface{}) (bool, error) {
+	if r.event != "" && !strings.EqualFold(r.event, event) {
+		return false, nil
+	}
+	if r.when == nil {
+		return true, nil
+	}
+
+	return r.when.evalBool(env)
+}
+
+// fire returns whether the rule is out of its cooldown, marking it as fired
+func (r *rule) fire(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cooldown > 0 && now.Sub(r.lastFired) < r.cooldown {
+		return false
+	}
+	r.lastFired = now
+
+	return true
+}
+
+func (r *rule) notification(text string, ts time.Time) notification {
+	n := notification{Category: categoryRules, Text: text, Timestamp: ts}
+	switch r.severity {
+	case severityInfo:
+		n.Silent = true
+	case severityCritical:
+		n.Category = categoryCritical
+	}
+
+	return n
+}
+
+// applyRules evaluates the rules against the fields of the journal event, including those
+// without a struct in the journal package, sending a notification for each matching rule
+func (e *Notifier) applyRules(ev journal.Event, skipNotify bool) {
+	if skipNotify || len(e.rules) == 0 {
+		return
+	}
+
+	raw, err := journal.Fields(ev)
+	if err != nil {
+		log.Infof("[ERROR] cannot read the fields of %s: %v", ev.EventName(), err)
+		return
+	}
+	event := ev.EventName()
+
+	stats := e.sessionStats()
+	env := make(map[string]interface{}, len(raw)+1)
+	data := make(map[string]interface{}, len(raw)+1)
+	for k, v := range raw {
+		env[k] = v
+		data[k] = v
+	}
+	env["Session"] = stats.values()
+	data["Session"] = stats
+
+	for _, r := range e.rules {
+		ok, err := r.match(event, env)
+		if err != nil {
+			log.Infof("[ERROR] cannot evaluate rule %s on %s: %v", r.name, event, err)
+			continue
+		}
+		if !ok || !r.fire(time.Now()) {
+			continue
+		}
+
+		var b strings.Builder
+		if err := r.message.Execute(&b, data); err != nil {
+			log.Infof("[ERROR] cannot execute the message of rule %s: %v", r.name, err)
+			continue
+		}
+
+		n := r.notification(b.String(), ev.Time())
+		n.Event = event
+		if err := e.notify(n, skipNotify); err != nil {
+			log.Infoln("[ERROR]", err)
+		}
+	}
+}
+
+// values returns the session counters as numbers, as seen by the rule conditions. The
+// duration is in seconds, Wanted is whether the commander is wanted in any faction.
+func (s sessionStats) values() map[string]interface{} {
+	return map[string]interface{}{
+		"Kills":          float64(s.Kills),
+		"Bounties":       float64(s.Bounties),
+		"ActiveMissions": float64(s.ActiveMissions),
+		"MissionsReward": float64(s.MissionsReward),
+		"Duration":       s.Duration.Seconds(),
+		"Wanted":         len(s.Wanted) > 0,
+		"Fines":          float64(s.Fines),
+		"CrewWages":      float64(s.CrewWages),
+		"Unclaimed":      float64(s.Unclaimed),
+		"BondKills":      float64(s.BondKills),
+		"Bonds":          float64(s.Bonds),
+	}
+}
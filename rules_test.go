@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/language"
+)
+
+func Test_newRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RuleCfg
+		wantErr bool
+	}{
+		{name: "valid", cfg: RuleCfg{Event: "ShipTargeted", When: `PilotRank == "Elite"`, Message: "{{.PilotRank}} pilot", Severity: "Critical"}},
+		{name: "without condition", cfg: RuleCfg{Event: "Interdicted", Message: "Interdicted"}},
+		{name: "invalid condition", cfg: RuleCfg{When: `PilotRank ==`, Message: "x"}, wantErr: true},
+		{name: "invalid message", cfg: RuleCfg{Message: "{{.PilotRank"}, wantErr: true},
+		{name: "unknown field", cfg: RuleCfg{Event: "ShipTargeted", Message: "{{.PilotRnak}} pilot"}, wantErr: true},
+		{name: "unknown session counter", cfg: RuleCfg{Event: "ShipTargeted", Message: "{{.Session.Kils}} kills"}, wantErr: true},
+		{name: "fields of an unknown event", cfg: RuleCfg{Event: "CarrierJump", Message: "Jumped to {{.StarSystem}}"}},
+		{name: "empty message", cfg: RuleCfg{Event: "Died"}, wantErr: true},
+		{name: "unknown severity", cfg: RuleCfg{Message: "x", Severity: "urgent"}, wantErr: true},
+		{name: "negative cooldown", cfg: RuleCfg{Message: "x", Cooldown: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRules([]RuleCfg{tt.cfg}, language.English)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %t, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNotifier_applyRules(t *testing.T) {
+	rules, err := newRules([]RuleCfg{{
+		Name:     "elite",
+		Event:    "ShipTargeted",
+		When:     `PilotRank == "Elite" && Session.Kills >= 2`,
+		Message:  "{{.PilotName_Localised}} ({{.PilotRank}}) is targeting you, kills: {{.Session.Kills}}",
+		Cooldown: time.Hour,
+	}}, language.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elite := `{"timestamp":"2024-03-09T21:02:17Z","event":"ShipTargeted","TargetLocked":true,"PilotName_Localised":"Jameson","PilotRank":"Elite"}`
+
+	tests := []struct {
+		name    string
+		line    string
+		kills   int
+		wantMsg string
+	}{
+		{name: "not enough kills", line: elite, kills: 1},
+		{name: "other event", line: `{"timestamp":"2024-03-09T21:02:17Z","event":"Scanned","PilotRank":"Elite"}`, kills: 2},
+		{name: "other rank", line: `{"timestamp":"2024-03-09T21:02:17Z","event":"ShipTargeted","PilotRank":"Novice"}`, kills: 2},
+		{name: "match", line: elite, kills: 2, wantMsg: "Jameson (Elite) is targeting you, kills: 2"},
+		{name: "in cooldown", line: elite, kills: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := journal.Decode([]byte(tt.line))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bot := &mockBot{}
+			n := &Notifier{cfg: &Cfg{}, bot: bot, rules: rules, killedPirates: tt.kills}
+
+			n.applyRules(ev, false)
+
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func Test_ruleNotification(t *testing.T) {
+	tests := []struct {
+		severity     string
+		wantCategory string
+		wantSilent   bool
+	}{
+		{severity: severityInfo, wantCategory: categoryRules, wantSilent: true},
+		{severity: severityWarning, wantCategory: categoryRules},
+		{severity: severityCritical, wantCategory: categoryCritical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.severity, func(t *testing.T) {
+			n := (&rule{severity: tt.severity}).notification("text", time.Now())
+			if n.Category != tt.wantCategory || n.Silent != tt.wantSilent {
+				t.Fatalf("want: %s, %t, got: %s, %t", tt.wantCategory, tt.wantSilent, n.Category, n.Silent)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package notifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ScheduleCfg defines time windows in which only some categories of notifications are
+// delivered. Outside the windows every notification is delivered.
+type ScheduleCfg struct {
+	Timezone string // IANA timezone of the windows, e.g. "Europe/Rome" (default: local time)
+	Windows  []ScheduleWindowCfg
+}
+
+// ScheduleWindowCfg is a time window of the schedule. Categories not listed in Deliver or
+// Silent are suppressed and reported in a summary when the window ends.
+type ScheduleWindowCfg struct {
+	Days    []string // weekdays on which the window starts, e.g. "mon" (default: every day)
+	Start   string   // start time, e.g. "23:00"
+	End     string   // end time, e.g. "07:30", it can be before Start to span midnight
+	Deliver []string // categories delivered normally, e.g. "critical"
+	Silent  []string // categories delivered without sound
+}
+
+type policy int
+
+const (
+	policyDeliver policy = iota
+	policySilent
+	policySuppress
+)
+
+type scheduleWindow struct {
+	days    map[time.Weekday]bool
+	start   int // minutes since midnight
+	end     int
+	deliver map[string]bool
+	silent  map[string]bool
+}
+
+type schedule struct {
+	loc     *time.Location
+	windows []scheduleWindow
+
+	mu         sync.Mutex
+	suppressed []notification
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// newSchedule parses the schedule config, returning nil when there are no windows
+func newSchedule(cfg ScheduleCfg) (*schedule, error) {
+	if len(cfg.Windows) == 0 {
+		return nil, nil
+	}
+
+	loc := time.Local
+	if cfg.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule timezone: %v", err)
+		}
+		loc = l
+	}
+
+	s := &schedule{loc: loc}
+	for i, w := range cfg.Windows {
+		window, err := parseScheduleWindow(w)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule window %d: %v", i+1, err)
+		}
+		s.windows = append(s.windows, window)
+	}
+
+	return s, nil
+}
+
+func parseScheduleWindow(cfg ScheduleWindowCfg) (scheduleWindow, error) {
+	w := scheduleWindow{
+		days:    make(map[time.Weekday]bool),
+		deliver: make(map[string]bool),
+		silent:  make(map[string]bool),
+	}
+
+	for _, d := range cfg.Days {
+		// Accept both short and full names, e.g. "mon" and "Monday"
+		day := strings.ToLower(d)
+		if len(day) > 3 {
+			day = day[:3]
+		}
+		wd, ok := weekdays[day]
+		if !ok {
+			return w, fmt.Errorf("unknown day %q", d)
+		}
+		w.days[wd] = true
+	}
+	if len(w.days) == 0 {
+		for _, wd := range weekdays {
+			w.days[wd] = true
+		}
+	}
+
+	var err error
+	if w.start, err = parseClock(cfg.Start); err != nil {
+		return w, fmt.Errorf("invalid start: %v", err)
+	}
+	if w.end, err = parseClock(cfg.End); err != nil {
+		return w, fmt.Errorf("invalid end: %v", err)
+	}
+
+	for _, c := range cfg.Deliver {
+		if !isCategory(c) {
+			return w, fmt.Errorf("unknown category %q", c)
+		}
+		w.deliver[c] = true
+	}
+	for _, c := range cfg.Silent {
+		if !isCategory(c) {
+			return w, fmt.Errorf("unknown category %q", c)
+		}
+		w.silent[c] = true
+	}
+
+	return w, nil
+}
+
+// parseClock parses a "HH:MM" time, returning the minutes since midnight
+func parseClock(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("%q is not in the HH:MM format", s)
+	}
+
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("%q is not in the HH:MM format", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("%q is not in the HH:MM format", s)
+	}
+
+	return h*60 + m, nil
+}
+
+// contains returns whether the time, already in the schedule timezone, is in the window
+func (w scheduleWindow) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	if w.start <= w.end {
+		return w.days[today] && m >= w.start && m < w.end
+	}
+
+	// The window spans midnight
+	return (w.days[today] && m >= w.start) || (w.days[yesterday] && m < w.end)
+}
+
+// policy returns how the notification must be delivered at the given time. When more windows
+// contain the time, the least restrictive policy wins.
+func (s *schedule) policy(n notification, t time.Time) policy {
+	t = t.In(s.loc)
+
+	p := policyDeliver
+	found := false
+	for _, w := range s.windows {
+		if !w.contains(t) {
+			continue
+		}
+
+		var wp policy
+		switch {
+		case w.deliver[n.Category]:
+			wp = policyDeliver
+		case w.silent[n.Category]:
+			wp = policySilent
+		default:
+			wp = policySuppress
+		}
+
+		if !found || wp < p {
+			p = wp
+		}
+		found = true
+	}
+
+	return p
+}
+
+func (s *schedule) active(t time.Time) bool {
+	t = t.In(s.loc)
+	for _, w := range s.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suppress holds the notification for the summary sent when the window ends
+func (s *schedule) suppress(n notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.suppressed = append(s.suppressed, n)
+}
+
+// flush returns the suppressed notifications once no window is active anymore
+func (s *schedule) flush(t time.Time) []notification {
+	if s.active(t) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	suppressed := s.suppressed
+	s.suppressed = nil
+
+	return suppressed
+}
+
+// watchSchedule periodically sends the summary of the notifications suppressed during the
+// schedule windows, once they end
+func (e *Notifier) watchSchedule() {
+	if e.schedule == nil {
+		return
+	}
+
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			if err := e.sendSummary(now); err != nil {
+				log.Infoln("[ERROR]", err)
+			}
+		}
+	}()
+}
+
+// sendSummary sends the summary of the suppressed notifications, when no window is active
+// anymore. The session counters are read holding the lock, as readJournal updates them.
+func (e *Notifier) sendSummary(now time.Time) error {
+	suppressed := e.schedule.flush(now)
+	if len(suppressed) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	data := e.quietSummaryData(suppressed)
+	prefix := e.withPrefix("")
+	e.mu.Unlock()
+
+	msg, err := e.execute(quietSummaryTemplate, data)
+	if err != nil {
+		return fmt.Errorf("cannot build the summary of suppressed notifications: %v", err)
+	}
+
+	// The summary is sent after the window, bypassing the throttle and the schedule of notify
+	return e.send(notification{Category: categorySummary, Source: e.journalPath, Text: prefix + msg, Timestamp: now})
+}
+
+// suppressedItem is a notification suppressed by the schedule, as seen by the summary template
+type suppressedItem struct {
+	Time string // time of the notification in the schedule timezone, e.g. "03:12"
+	Text string
+}
+
+func (e *Notifier) quietSummaryData(suppressed []notification) map[string]interface{} {
+	items := make([]suppressedItem, 0, len(suppressed))
+	for _, n := range suppressed {
+		items = append(items, suppressedItem{
+			Time: n.Timestamp.In(e.schedule.loc).Format("15:04"),
+			Text: n.Text,
+		})
+	}
+
+	return map[string]interface{}{
+		"Suppressed": items,
+		"Session":    e.sessionStats(),
+	}
+}
@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_schedulePolicy(t *testing.T) {
+	s, err := newSchedule(ScheduleCfg{
+		Timezone: "UTC",
+		Windows: []ScheduleWindowCfg{
+			{
+				Days:    []string{"mon", "Tuesday"},
+				Start:   "23:00",
+				End:     "07:30",
+				Deliver: []string{categoryCritical},
+				Silent:  []string{categoryHull},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2022-10-03 is a Monday
+	monday := func(h, m int) time.Time { return time.Date(2022, 10, 3, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		category string
+		t        time.Time
+		want     policy
+	}{
+		{name: "before the window", category: categoryShields, t: monday(22, 59), want: policyDeliver},
+		{name: "critical in the window", category: categoryCritical, t: monday(23, 0), want: policyDeliver},
+		{name: "silent in the window", category: categoryHull, t: monday(23, 30), want: policySilent},
+		{name: "suppressed after midnight", category: categoryShields, t: monday(24+3, 12), want: policySuppress},
+		{name: "after the window", category: categoryShields, t: monday(24+7, 30), want: policyDeliver},
+		{name: "monday morning belongs to sunday", category: categoryShields, t: monday(3, 0), want: policyDeliver},
+		{name: "wednesday morning belongs to tuesday", category: categoryKills, t: monday(48+1, 0), want: policySuppress},
+		{name: "wednesday night", category: categoryKills, t: monday(48+23, 0), want: policyDeliver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.policy(notification{Category: tt.category}, tt.t); got != tt.want {
+				t.Fatalf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+
+	s.suppress(notification{Category: categoryShields, Text: "Shields are down!"})
+	if got := s.flush(monday(24+3, 13)); len(got) != 0 {
+		t.Fatalf("flushed %d notifications while the window is active", len(got))
+	}
+	if got := s.flush(monday(24+7, 31)); len(got) != 1 {
+		t.Fatalf("want 1 flushed notification, got: %d", len(got))
+	}
+}
+
+func Test_newScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ScheduleCfg
+	}{
+		{name: "invalid timezone", cfg: ScheduleCfg{Timezone: "Mars/Olympus", Windows: []ScheduleWindowCfg{{Start: "23:00", End: "07:00"}}}},
+		{name: "invalid day", cfg: ScheduleCfg{Windows: []ScheduleWindowCfg{{Days: []string{"someday"}, Start: "23:00", End: "07:00"}}}},
+		{name: "invalid time", cfg: ScheduleCfg{Windows: []ScheduleWindowCfg{{Start: "25:00", End: "07:00"}}}},
+		{name: "invalid category", cfg: ScheduleCfg{Windows: []ScheduleWindowCfg{{Start: "23:00", End: "07:00", Deliver: []string{"pirates"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newSchedule(tt.cfg); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func Test_sendSummaryWaitsForJournal(t *testing.T) {
+	s, err := newSchedule(ScheduleCfg{
+		Timezone: "UTC",
+		Windows:  []ScheduleWindowCfg{{Start: "23:00", End: "07:30"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := &mockBot{}
+	n := &Notifier{bot: bot, schedule: s}
+
+	night := time.Date(2022, 10, 3, 3, 0, 0, 0, time.UTC)
+	s.suppress(notification{Category: categoryShields, Text: "Shields are down!", Timestamp: night})
+
+	// The journal goroutine is processing an event
+	n.mu.Lock()
+	done := make(chan error)
+	go func() { done <- n.sendSummary(night.Add(5 * time.Hour)) }()
+
+	select {
+	case <-done:
+		t.Fatalf("summary sent while the session state is locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	n.mu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bot.sentMsg, "Shields are down!") {
+		t.Fatalf("want: the suppressed notification, got: %v", bot.sentMsg)
+	}
+}
+
+func Test_sendSummaryPrefix(t *testing.T) {
+	s, err := newSchedule(ScheduleCfg{
+		Timezone: "UTC",
+		Windows:  []ScheduleWindowCfg{{Start: "23:00", End: "07:30"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := &mockBot{}
+	n := &Notifier{cfg: &Cfg{}, bot: bot, schedule: s, prefix: true, commander: "Jameson"}
+
+	night := time.Date(2022, 10, 3, 3, 0, 0, 0, time.UTC)
+	s.suppress(notification{Category: categoryShields, Text: "[CMDR Jameson] Shields are down!", Timestamp: night})
+
+	if err := n.sendSummary(night.Add(5 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(bot.sentMsg, "[CMDR Jameson] ") {
+		t.Fatalf("want: the summary prefixed with the commander, got: %v", bot.sentMsg)
+	}
+}
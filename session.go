@@ -0,0 +1,109 @@
+package notifier
+
+import (
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// When a session is very long, the game continues it in a new journal file: the old file ends
+// with a Continued event and the new one starts with a Fileheader with the next part number.
+// The counters are carried across the parts, so that the totals of the session are kept.
+
+// sessionFiles returns the journal files of the session of the current file, from its first
+// part to the current file
+func (e *Notifier) sessionFiles() []string {
+	current := e.position.File
+	session := []string{current}
+
+	files, err := journal.Files(e.journalPath)
+	if err != nil {
+		return session
+	}
+
+	i := len(files) - 1
+	for i >= 0 && files[i] != current {
+		i--
+	}
+
+	for ; i > 0; i-- {
+		header, err := journal.ReadFileheader(filepath.Join(e.journalPath, files[i]))
+		if err != nil || header.Part <= 1 {
+			break
+		}
+
+		prev, err := journal.ReadFileheader(filepath.Join(e.journalPath, files[i-1]))
+		if err != nil || prev.Part != header.Part-1 {
+			log.Infof("Cannot find part %d of the session of %s", header.Part-1, files[i])
+			break
+		}
+
+		session = append([]string{files[i-1]}, session...)
+	}
+
+	return session
+}
+
+// journalRotated is called for the first event read from a new journal file. The counters and
+// the state of the game are reset, unless the new file continues the session of the previous
+// one.
+func (e *Notifier) journalRotated(entry journal.Entry) {
+	continued := e.continued
+	if header, ok := entry.Event.(*journal.Fileheader); ok && header.Part > 1 {
+		continued = true
+	}
+	e.continued = false
+
+	if continued {
+		log.Infof("Session continues in journal file %s, keeping the session counters", entry.Position.File)
+		return
+	}
+
+	log.Infoln("Found new journal file:", entry.Position.File)
+	e.initCounters()
+	e.resetGame()
+}
+
+// resetGame forgets the state of the ship and of the commander, unknown until the new game
+// reports it
+func (e *Notifier) resetGame() {
+	e.loadout = nil
+	e.credits = -1
+	e.rebuyChecked = false
+	e.cargo = -1
+	e.cargoLow = false
+	e.crew = crewState{}
+	e.heat = heatState{}
+}
+
+func continuedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	e.continued = true
+
+	return nil
+}
+
+// setCommander sets the commander of the journal from the Commander and LoadGame events,
+// unless it is configured
+func (e *Notifier) setCommander(ev journal.Event) {
+	var name string
+	switch j := ev.(type) {
+	case *journal.Commander:
+		name = j.Name
+	case *journal.LoadGame:
+		name = j.Commander
+	}
+
+	if name == "" || name == e.commander || e.fixedCommander {
+		return
+	}
+
+	log.Infof("Journal %s belongs to CMDR %s", e.journalPath, name)
+	e.commander = name
+}
+
+func commanderEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	e.setCommander(ev)
+
+	return nil
+}
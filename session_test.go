@@ -0,0 +1,192 @@
+package notifier
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func TestNotifier_initNotifierContinued(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	bounty := `{"timestamp":"2024-03-09T21:04:02Z","event":"Bounty","TotalReward":1000,"Target":"x","VictimFaction":"y"}` + "\n"
+
+	// An older session, not part of the current one
+	writeFile(t, filepath.Join(dir, "Journal.2024-03-08T100000.01.log"),
+		`{"timestamp":"2024-03-08T10:00:00Z","event":"Fileheader","part":1}`+"\n"+bounty,
+		now.Add(-3*time.Hour))
+	writeFile(t, filepath.Join(dir, "Journal.2024-03-09T201233.01.log"),
+		`{"timestamp":"2024-03-09T20:12:33Z","event":"Fileheader","part":1}`+"\n"+bounty+bounty+
+			`{"timestamp":"2024-03-09T23:59:58Z","event":"Continued","Part":2}`+"\n",
+		now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(dir, "Journal.2024-03-10T000000.02.log"),
+		`{"timestamp":"2024-03-10T00:00:00Z","event":"Fileheader","part":2}`+"\n"+bounty,
+		now.Add(-time.Hour))
+
+	n := &Notifier{
+		cfg:         &Cfg{},
+		journalPath: dir,
+		position:    journal.Position{File: "Journal.2024-03-10T000000.02.log"},
+	}
+	n.initNotifier()
+
+	if n.killedPirates != 3 || n.totalPiratesReward != 3000 {
+		t.Fatalf("want 3 kills and 3000 CR, got: %d kills and %d CR", n.killedPirates, n.totalPiratesReward)
+	}
+	if n.position.File != "Journal.2024-03-10T000000.02.log" || n.position.Offset == 0 {
+		t.Fatalf("want the position at the end of the last part, got: %+v", n.position)
+	}
+}
+
+func TestNotifier_journalRotated(t *testing.T) {
+	header := func(part int) journal.Entry {
+		return journal.Entry{Event: &journal.Fileheader{Header: journal.Header{Event: journal.FileheaderEvent}, Part: part}}
+	}
+
+	tests := []struct {
+		name      string
+		continued bool
+		entry     journal.Entry
+		wantKills int
+	}{
+		{name: "new session", entry: header(1), wantKills: 0},
+		{name: "after Continued", continued: true, entry: header(2), wantKills: 5},
+		{name: "part without Continued", entry: header(3), wantKills: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Notifier{cfg: &Cfg{}, killedPirates: 5, continued: tt.continued}
+
+			n.journalRotated(tt.entry)
+
+			if n.killedPirates != tt.wantKills {
+				t.Fatalf("want %d kills, got: %d", tt.wantKills, n.killedPirates)
+			}
+			if n.continued {
+				t.Fatal("want the continued flag reset")
+			}
+		})
+	}
+}
+
+func TestNotifier_commanderPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       *Notifier
+		ev      journal.Event
+		wantMsg string
+	}{
+		{
+			name:    "single journal",
+			n:       &Notifier{cfg: &Cfg{}},
+			ev:      &journal.LoadGame{Header: journal.Header{Event: journal.LoadGameEvent}, Commander: "Foo"},
+			wantMsg: "Your ship has been destroyed",
+		},
+		{
+			name:    "name from LoadGame",
+			n:       &Notifier{cfg: &Cfg{}, prefix: true},
+			ev:      &journal.LoadGame{Header: journal.Header{Event: journal.LoadGameEvent}, Commander: "Foo"},
+			wantMsg: "[CMDR Foo] Your ship has been destroyed",
+		},
+		{
+			name:    "name from Commander",
+			n:       &Notifier{cfg: &Cfg{}, prefix: true, commander: "Old"},
+			ev:      &journal.Commander{Header: journal.Header{Event: journal.CommanderEvent}, Name: "Bar"},
+			wantMsg: "[CMDR Bar] Your ship has been destroyed",
+		},
+		{
+			name:    "configured name",
+			n:       &Notifier{cfg: &Cfg{}, prefix: true, commander: "Baz", fixedCommander: true},
+			ev:      &journal.LoadGame{Header: journal.Header{Event: journal.LoadGameEvent}, Commander: "Foo"},
+			wantMsg: "[CMDR Baz] Your ship has been destroyed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			tt.n.bot = bot
+
+			if err := commanderEvent(tt.n, tt.ev, false); err != nil {
+				t.Fatal(err)
+			}
+			if err := diedEvent(tt.n, &journal.Died{Header: journal.Header{Event: journal.DiedEvent}}, false); err != nil {
+				t.Fatal(err)
+			}
+
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("want: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func TestJournalCfg_botCfg(t *testing.T) {
+	telegram := &Cfg{NotificationService: "telegram", TelegramChannelId: 1, GotifyToken: "default"}
+	gotify := &Cfg{NotificationService: "gotify", TelegramChannelId: 1, GotifyToken: "default"}
+
+	tests := []struct {
+		name        string
+		j           JournalCfg
+		cfg         *Cfg
+		wantChannel int64
+		wantToken   string
+		wantNil     bool
+	}{
+		{name: "default telegram chat", j: JournalCfg{}, cfg: telegram, wantNil: true},
+		{name: "same telegram chat", j: JournalCfg{TelegramChannelId: 1}, cfg: telegram, wantNil: true},
+		{name: "gotify token with telegram", j: JournalCfg{GotifyToken: "other"}, cfg: telegram, wantNil: true},
+		{name: "own telegram chat", j: JournalCfg{TelegramChannelId: 2}, cfg: telegram, wantChannel: 2, wantToken: "default"},
+		{name: "own gotify application", j: JournalCfg{GotifyToken: "other"}, cfg: gotify, wantChannel: 1, wantToken: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.j.botCfg(tt.cfg)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("want: nil, got: %+v", got)
+				}
+				return
+			}
+
+			if got == nil || got.TelegramChannelId != tt.wantChannel || got.GotifyToken != tt.wantToken {
+				t.Fatalf("want: %d, %s, got: %+v", tt.wantChannel, tt.wantToken, got)
+			}
+		})
+	}
+}
+
+func TestNotifier_journalRotatedResetsGame(t *testing.T) {
+	bot := &mockBot{}
+	n := &Notifier{
+		cfg:          &Cfg{Cargo: CargoCfg{Undock: true}},
+		bot:          bot,
+		loadout:      &journal.Loadout{Ship: "type9_military", Rebuy: 12600000},
+		credits:      9000000,
+		rebuyChecked: true,
+		cargo:        0,
+		crew:         crewState{known: true, active: "Kara Voss"},
+	}
+
+	n.journalRotated(journal.Entry{Event: &journal.Fileheader{Header: journal.Header{Event: journal.FileheaderEvent}, Part: 1}})
+
+	if n.loadout != nil || n.credits != -1 || n.rebuyChecked || n.cargo != -1 || n.crew.known {
+		t.Fatalf("want the state of the previous game reset, got: %+v, %d CR, %d t, %+v", n.loadout, n.credits, n.cargo, n.crew)
+	}
+
+	// Nothing is reported about the previous game
+	if err := n.checkRebuy(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := undockedEvent(n, &journal.Undocked{StationName: "Ford Terminal"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if bot.sentMsg != "" {
+		t.Fatalf("want no message, got: %s", bot.sentMsg)
+	}
+}
@@ -0,0 +1,460 @@
+package notifier
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Names of the notification templates, also used as keys of the [templates] config section
+const (
+	hullDamageTemplate         = "hull_damage"
+	diedTemplate               = "died"
+	shieldsUpTemplate          = "shields_up"
+	shieldsDownTemplate        = "shields_down"
+	killsTemplate              = "kills"
+	missionsCompletedTemplate  = "missions_completed"
+	startupTemplate            = "startup"
+	quietSummaryTemplate       = "quiet_summary"
+	lateDeliveryTemplate       = "late_delivery"
+	repeatedTemplate           = "repeated"
+	interdictedTemplate        = "interdicted"
+	escapeInterdictionTemplate = "escape_interdiction"
+	interdictionTemplate       = "interdiction"
+	threatTemplate             = "threat"
+	underAttackTemplate        = "under_attack"
+	scannedTemplate            = "scanned"
+	cargoLowTemplate           = "cargo_low"
+	emptyCargoTemplate         = "empty_cargo"
+	heatWarningTemplate        = "heat_warning"
+	heatDamageTemplate         = "heat_damage"
+	heatCriticalTemplate       = "heat_critical"
+	systemsShutdownTemplate    = "systems_shutdown"
+	rebootRepairTemplate       = "reboot_repair"
+	commitCrimeTemplate        = "commit_crime"
+	crimeVictimTemplate        = "crime_victim"
+	wantedClearedTemplate      = "wanted_cleared"
+	crewInactiveTemplate       = "crew_inactive"
+	crewJoinedTemplate         = "crew_joined"
+	crewRoleTemplate           = "crew_role"
+	readinessTemplate          = "readiness"
+	rebuyTemplate              = "rebuy"
+	unclaimedTemplate          = "unclaimed_bounties"
+	combatBondsTemplate        = "combat_bonds"
+)
+
+// defaultTemplates contains the default (English) text of each notification. Translations
+// are in localizedTemplates.
+var defaultTemplates = map[string]string{
+	hullDamageTemplate:        `{{if .Fighter}}Fighter{{else}}Ship{{end}} hull damage detected, integrity is {{percent .Health}}`,
+	diedTemplate:              `Your ship has been destroyed{{with .Rebuy}}, rebuy {{credits .}} CR{{end}}{{with .Unclaimed}}, {{credits .}} CR of unclaimed bounties lost{{end}}`,
+	shieldsUpTemplate:         `Shields are up again`,
+	shieldsDownTemplate:       `Shields are down!`,
+	killsTemplate:             "Total rewards: {{credits .Session.Bounties}} credits\nPirates killed: {{.Session.Kills}}{{with .Session.Wanted}}\nWanted in: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}\nUnpaid fines: {{credits .}} credits{{end}}{{with .Session.CrewWages}}\nCrew wages: {{credits .}} credits{{end}}{{with .Session.CrewRanks}}\nCrew promotions: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}",
+	combatBondsTemplate:       "Combat bonds: {{credits .Session.Bonds}} credits\nEnemies killed: {{.Session.BondKills}}{{range .Session.Sides}}\n- {{.Faction}}: {{credits .Reward}} credits, kills: {{.Kills}}{{end}}",
+	missionsCompletedTemplate: `No more active missions, go collect new ones!`,
+	startupTemplate: `ED-AFK-Notifier v{{.Version}} started
+
+Configuration:
+- Notification service: {{.Service}}
+- Fighter notifications: {{.FighterNotifs}}
+- Shield notifications: {{.ShieldsNotifs}}
+- Kill notifications: {{.KillsNotifs}}{{if .KillsNotifs}}{{if .KillsSilentNotifs}} (silent mode){{else}} (all kills){{end}}{{end}}`,
+	quietSummaryTemplate: `While you were away, {{len .Suppressed}} notifications were held back:
+{{range .Suppressed}}
+{{.Time}} {{.Text}}{{end}}{{if or .Session.Wanted .Session.Fines}}
+{{with .Session.Wanted}}
+Wanted in: {{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}{{with .Session.Fines}}
+Unpaid fines: {{credits .}} credits{{end}}{{end}}{{if .Session.BondKills}}
+
+Combat bonds: {{credits .Session.Bonds}} credits, enemies killed: {{.Session.BondKills}}{{end}}{{if or .Session.CrewWages .Session.CrewRanks}}
+{{with .Session.CrewWages}}
+Crew wages: {{credits .}} credits{{end}}{{with .Session.CrewRanks}}
+Crew promotions: {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}{{end}}`,
+	lateDeliveryTemplate:       `Delivered late, this happened at {{.Time}}: {{.Text}}`,
+	repeatedTemplate:           `(repeated {{.Count}} times)`,
+	interdictedTemplate:        `Interdicted by {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}{{if not .IsThargoid}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}}){{end}}, {{if .Submitted}}you submitted{{else}}you couldn't escape{{end}}`,
+	escapeInterdictionTemplate: `Escaped the interdiction of {{if .IsPlayer}}CMDR {{end}}{{or .Interdictor_Localised .Interdictor}}`,
+	interdictionTemplate:       `{{if .Success}}Interdiction succeeded{{else}}Interdiction failed{{end}}: {{if .IsPlayer}}CMDR {{end}}{{or .Interdicted_Localised .Interdicted}} ({{combatRank .CombatRank}}{{with .Faction}}, {{.}}{{end}})`,
+	threatTemplate:             `Dangerous contact: {{or .Ship_Localised .Ship}}{{with or .PilotName_Localised .PilotName}} piloted by {{.}}{{end}}{{with .PilotRank}} ({{combatRank .}}){{end}}{{with .LegalStatus}}, {{.}}{{end}}{{if .HullHealth}}, shields {{percent100 .ShieldHealth}}, hull {{percent100 .HullHealth}}{{end}}`,
+	underAttackTemplate:        `{{if eq .Target "Fighter"}}Your fighter is under attack{{else if eq .Target "Mothership"}}Your ship is under attack{{else}}You are under attack{{end}}`,
+	scannedTemplate:            `Your ship has been scanned{{with .ScanType}} ({{.}} scan){{end}}`,
+	cargoLowTemplate:           `Cargo is down to {{.Count}} t, pirates may stop attacking`,
+	emptyCargoTemplate:         `You undocked{{with .StationName}} from {{.}}{{end}} with an empty cargo hold, pirates won't attack`,
+	heatWarningTemplate:        `Ship is overheating!`,
+	heatDamageTemplate:         `Heat damage! Modules are being damaged`,
+	heatCriticalTemplate:       `Heat damage for {{.Seconds}} seconds, modules are failing!`,
+	systemsShutdownTemplate:    `Ship systems have been shut down!`,
+	rebootRepairTemplate:       `Reboot/repair completed: {{if .Modules}}{{range $i, $m := .Modules}}{{if $i}}, {{end}}{{$m}}{{end}} back online{{else}}no module repaired{{end}}`,
+	commitCrimeTemplate:        `Crime committed: {{crimeType .CrimeType}}{{with or .Victim_Localised .Victim}} against {{.}}{{end}}{{if .Bounty}}, bounty of {{credits .Bounty}} CR, you are wanted in {{.Faction}}{{else if .Fine}}, fine of {{credits .Fine}} CR{{with .Faction}} from {{.}}{{end}}{{end}}`,
+	crimeVictimTemplate:        `{{or .Offender_Localised .Offender}} committed {{crimeType .CrimeType}} against you{{if .Bounty}}, bounty of {{credits .Bounty}} CR{{end}}`,
+	wantedClearedTemplate:      `You are no longer wanted{{with .Faction}} in {{.}}{{end}}`,
+	crewInactiveTemplate:       `No crew member is active while a fighter bay is fitted, the fighter can't be launched`,
+	crewJoinedTemplate:         `CMDR {{.Crew}} joined your crew`,
+	crewRoleTemplate:           `CMDR {{.Crew}} is now in the {{.Role}} role`,
+	rebuyTemplate:              `Not enough credits for the rebuy of your {{or .ShipName .Ship}}: {{credits .Credits}} CR available, the rebuy costs {{credits .Rebuy}} CR`,
+	unclaimedTemplate:          "Unclaimed bounties: {{credits .Unclaimed}} CR, over {{credits .Threshold}} CR, time to cash them in{{range .Factions}}\n- {{.Faction}}: {{credits .Amount}} CR{{end}}",
+	readinessTemplate: `Pre-flight check{{with .StationName}} leaving {{.}}{{end}}:{{with index .Checks "fighter"}}
+{{.}} Fighter hangar{{end}}{{with index .Checks "crew"}}
+{{.}} Crew{{with $.Crew}}: {{.}} active{{end}}{{end}}{{with index .Checks "cargo"}}
+{{.}} Bait cargo: {{if ge $.Cargo 0}}{{$.Cargo}} t{{else}}unknown{{end}}{{if $.EmptyHold}}, pirates won't attack{{end}}{{end}}{{with index .Checks "missions"}}
+{{.}} Massacre missions: {{$.Massacres}}{{end}}{{with index .Checks "shields"}}
+{{.}} Shield generator{{end}}`,
+}
+
+// templateEvents are the journal events rendered by the notification templates, used to
+// validate the templates at startup
+var templateEvents = map[string]string{
+	hullDamageTemplate:         journal.HullDamageEvent,
+	diedTemplate:               journal.DiedEvent,
+	shieldsUpTemplate:          journal.ShieldStateEvent,
+	shieldsDownTemplate:        journal.ShieldStateEvent,
+	killsTemplate:              journal.BountyEvent,
+	combatBondsTemplate:        journal.FactionKillBondEvent,
+	missionsCompletedTemplate:  journal.MissionCompletedEvent,
+	interdictedTemplate:        journal.InterdictedEvent,
+	escapeInterdictionTemplate: journal.EscapeInterdictionEvent,
+	interdictionTemplate:       journal.InterdictionEvent,
+	threatTemplate:             journal.ShipTargetedEvent,
+	underAttackTemplate:        journal.UnderAttackEvent,
+	scannedTemplate:            journal.ScannedEvent,
+	cargoLowTemplate:           journal.CargoEvent,
+	emptyCargoTemplate:         journal.UndockedEvent,
+	heatWarningTemplate:        journal.HeatWarningEvent,
+	heatDamageTemplate:         journal.HeatDamageEvent,
+	heatCriticalTemplate:       journal.HeatDamageEvent,
+	systemsShutdownTemplate:    journal.SystemsShutdownEvent,
+	rebootRepairTemplate:       journal.RebootRepairEvent,
+	commitCrimeTemplate:        journal.CommitCrimeEvent,
+	crimeVictimTemplate:        journal.CrimeVictimEvent,
+	wantedClearedTemplate:      journal.PayBountiesEvent,
+	crewInactiveTemplate:       journal.CrewAssignEvent,
+	crewJoinedTemplate:         journal.CrewMemberJoinsEvent,
+	crewRoleTemplate:           journal.CrewMemberRoleChangeEvent,
+	readinessTemplate:          journal.UndockedEvent,
+	rebuyTemplate:              journal.LoadoutEvent,
+	unclaimedTemplate:          journal.BountyEvent,
+}
+
+// templateValues are sample values added to the event fields by renderWith, keyed by
+// template name
+var templateValues = map[string]map[string]interface{}{
+	diedTemplate:         {"Rebuy": int64(0), "Unclaimed": int64(0)},
+	heatCriticalTemplate: {"Seconds": 20},
+	rebuyTemplate:        {"Credits": int64(0)},
+	unclaimedTemplate:    {"Unclaimed": int64(0), "Threshold": int64(0), "Factions": []factionAmount{{Faction: "Ngalinn Crimson Boys"}}},
+	readinessTemplate:    {"Checks": map[string]string{checkFighter: markPass}, "Crew": "", "Cargo": 0, "Massacres": 0, "EmptyHold": false},
+}
+
+// englishTemplates are used when the notifier has no parsed templates
+var englishTemplates = mustParseTemplates(nil, language.English)
+
+// sessionStats contains the session counters available to the templates as .Session
+type sessionStats struct {
+	Kills          int           // pirates killed
+	Bounties       int64         // total credits earned with bounties
+	ActiveMissions int           // missions still active
+	MissionsReward int64         // total credits earned completing missions
+	Duration       time.Duration // time since the notifier started
+	Wanted         []string      // factions in which the commander is wanted, sorted by name
+	Fines          int64         // unpaid fines, in credits
+	CrewWages      int64         // total credits paid to the NPC crew
+	CrewRanks      []string      // promotions of the NPC crew, e.g. "Kara Voss (Expert)"
+	Unclaimed      int64         // credits of bounties not redeemed yet, lost if the ship is destroyed
+	BondKills      int           // kills in conflict zones
+	Bonds          int64         // total credits earned with combat bonds
+	Sides          []sideStats   // kills and combat bonds by side of the conflict, the most rewarding first
+}
+
+func templateFuncs(p *message.Printer) template.FuncMap {
+	return template.FuncMap{
+		"credits": func(v interface{}) (string, error) {
+			return formatCredits(p, v)
+		},
+		"percent": func(v interface{}) (string, error) {
+			return formatPercent(p, v)
+		},
+		"percent100": func(v interface{}) (string, error) {
+			return formatPercent100(p, v)
+		},
+		"duration": formatDuration,
+		"combatRank": func(v interface{}) (string, error) {
+			return formatCombatRank(p, v)
+		},
+		"crimeType": func(crimeType string) string {
+			return formatCrimeType(p, crimeType)
+		},
+	}
+}
+
+// parseTemplates parses the default templates in the given language, overridden by the user
+// templates keyed by template name. Templates are executed with sample data too, so that
+// errors are reported at startup rather than when sending.
+func parseTemplates(texts map[string]string, lang language.Tag) (map[string]*template.Template, error) {
+	for name := range texts {
+		if _, ok := defaultTemplates[name]; !ok {
+			return nil, fmt.Errorf("unknown template %q, valid templates are: %s", name, strings.Join(templateNames(), ", "))
+		}
+	}
+
+	funcs := templateFuncs(message.NewPrinter(lang))
+	templates := make(map[string]*template.Template)
+
+	for name, text := range defaultTemplates {
+		if localized, ok := localizedTemplates[lang][name]; ok {
+			text = localized
+		}
+		if custom, ok := texts[name]; ok {
+			text = custom
+		}
+
+		t, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the %s template: %v", name, err)
+		}
+
+		if err := t.Execute(&strings.Builder{}, sampleTemplateData(name)); err != nil {
+			return nil, fmt.Errorf("invalid %s template: %v", name, err)
+		}
+
+		templates[name] = t
+	}
+
+	return templates, nil
+}
+
+func mustParseTemplates(texts map[string]string, lang language.Tag) map[string]*template.Template {
+	templates, err := parseTemplates(texts, lang)
+	if err != nil {
+		panic(err)
+	}
+
+	return templates
+}
+
+func templateNames() []string {
+	names := make([]string, 0, len(defaultTemplates))
+	for name := range defaultTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sampleTemplateData(name string) map[string]interface{} {
+	switch name {
+	case startupTemplate:
+		return startupData("", &Cfg{})
+	case repeatedTemplate:
+		return map[string]interface{}{"Count": 4}
+	case lateDeliveryTemplate:
+		return map[string]interface{}{"Time": "03:12", "Text": "Shields are down!"}
+	case quietSummaryTemplate:
+		return map[string]interface{}{
+			"Suppressed": []suppressedItem{{Time: "03:12", Text: "Shields are down!"}},
+			"Session":    sessionStats{},
+		}
+	}
+
+	data, _ := eventData(journal.New(templateEvents[name]))
+	for k, v := range templateValues[name] {
+		data[k] = v
+	}
+	data["Session"] = sessionStats{}
+
+	return data
+}
+
+// render executes the named template with the fields of the journal event and the session
+// counters, using the user template when configured or the default one otherwise
+func (e *Notifier) render(name string, ev journal.Event) (string, error) {
+	return e.renderWith(name, ev, nil)
+}
+
+// renderWith is like render, adding the values to the data of the template, e.g. those
+// computed from previous events
+func (e *Notifier) renderWith(name string, ev journal.Event, values map[string]interface{}) (string, error) {
+	data, err := eventData(ev)
+	if err != nil {
+		return "", fmt.Errorf("cannot build data for the %s template: %v", name, err)
+	}
+	for k, v := range values {
+		data[k] = v
+	}
+	data["Session"] = e.sessionStats()
+
+	return e.execute(name, data)
+}
+
+func (e *Notifier) execute(name string, data map[string]interface{}) (string, error) {
+	templates := e.templates
+	if templates == nil {
+		templates = englishTemplates
+	}
+	t := templates[name]
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("cannot execute the %s template: %v", name, err)
+	}
+
+	return b.String(), nil
+}
+
+func (e *Notifier) sessionStats() sessionStats {
+	s := sessionStats{
+		Kills:          e.killedPirates,
+		Bounties:       e.totalPiratesReward,
+		ActiveMissions: e.activeMissions,
+		MissionsReward: e.totalMissionsReward,
+		Wanted:         e.wantedFactions(),
+		Fines:          e.unpaidFines(),
+		CrewWages:      e.crewWages,
+		CrewRanks:      e.crewRanks,
+		Unclaimed:      e.unclaimedTotal(),
+		BondKills:      e.bondKills,
+		Bonds:          e.totalBonds,
+		Sides:          e.sides(),
+	}
+	if !e.startTime.IsZero() {
+		s.Duration = time.Since(e.startTime)
+	}
+
+	return s
+}
+
+// eventData returns the fields of the journal event keyed by their journal name
+func eventData(ev journal.Event) (map[string]interface{}, error) {
+	return journal.Fields(ev)
+}
+
+func startupData(version string, cfg *Cfg) map[string]interface{} {
+	return map[string]interface{}{
+		"Version":           version,
+		"Service":           cfg.NotificationService,
+		"FighterNotifs":     cfg.FighterNotifs,
+		"ShieldsNotifs":     cfg.ShieldsNotifs,
+		"KillsNotifs":       cfg.KillsNotifs,
+		"KillsSilentNotifs": cfg.KillsSilentNotifs,
+	}
+}
+
+// formatCredits formats an amount of credits with the thousands separator of the language
+func formatCredits(p *message.Printer, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Sprintf("%d", int64(math.Round(f))), nil
+}
+
+// formatPercent formats a 0-1 ratio as a percentage, e.g. 0.4 -> "40%"
+func formatPercent(p *message.Printer, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Sprintf("%d%%", int(math.Round(f*100))), nil
+}
+
+// formatPercent100 formats a 0-100 value as a percentage, e.g. 41.3 -> "41%"
+func formatPercent100(p *message.Printer, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Sprintf("%d%%", int(math.Round(f))), nil
+}
+
+// formatDuration formats a duration, or a number of seconds, rounded to the minute
+func formatDuration(v interface{}) (string, error) {
+	d, ok := v.(time.Duration)
+	if !ok {
+		f, err := toFloat(v)
+		if err != nil {
+			return "", err
+		}
+		d = time.Duration(f * float64(time.Second))
+	}
+
+	d = d.Round(time.Minute)
+	h := d / time.Hour
+	m := (d - h*time.Hour) / time.Minute
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m), nil
+	}
+
+	return fmt.Sprintf("%dm", m), nil
+}
+
+// combatRanks are the names of the combat ranks of the journal, from 0 to 8
+var combatRanks = []string{"Harmless", "Mostly Harmless", "Novice", "Competent", "Expert", "Master", "Dangerous", "Deadly", "Elite"}
+
+// formatCombatRank returns the name of a combat rank in the language of the printer, from its
+// number or its English name, e.g. 7 or "Deadly" -> "Deadly"
+func formatCombatRank(p *message.Printer, v interface{}) (string, error) {
+	if name, ok := v.(string); ok {
+		rank := rankIndex(name)
+		if rank < 0 {
+			return name, nil
+		}
+		v = rank
+	}
+
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	rank := int(f)
+	if rank < 0 || rank >= len(combatRanks) {
+		return fmt.Sprintf("rank %d", rank), nil
+	}
+
+	return p.Sprintf(combatRanks[rank]), nil
+}
+
+// formatCrimeType returns the words of a crime type of the journal in the language of the
+// printer, e.g. "fireInNoFireZone" -> "fire in no fire zone". Crime types without a
+// translation are returned in English.
+func formatCrimeType(p *message.Printer, crimeType string) string {
+	var b strings.Builder
+	for i, r := range crimeType {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return p.Sprintf(b.String())
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case time.Duration:
+		return n.Seconds(), nil
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", v)
+	}
+}
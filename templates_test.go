@@ -0,0 +1,177 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+	"golang.org/x/text/language"
+)
+
+func Test_parseTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		texts   map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "valid template",
+			texts: map[string]string{killsTemplate: "{{.Session.Kills}} kills, {{credits .Session.Bounties}} CR"},
+		},
+		{
+			name:    "unknown template",
+			texts:   map[string]string{"unknown": "text"},
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			texts:   map[string]string{diedTemplate: "{{.Health"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			texts:   map[string]string{diedTemplate: "{{.NotAField}}"},
+			wantErr: true,
+		},
+		{
+			name:    "wrong helper argument",
+			texts:   map[string]string{diedTemplate: "{{credits .Event}}"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTemplates(tt.texts, language.English)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNotifier_render(t *testing.T) {
+	templates, err := parseTemplates(map[string]string{
+		killsTemplate: "{{.Session.Kills}} kills in {{duration .Session.Duration}}, last bounty {{credits .TotalReward}} CR",
+	}, language.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := &Notifier{
+		cfg:                &Cfg{},
+		templates:          templates,
+		startTime:          time.Now().Add(-95 * time.Minute),
+		killedPirates:      12,
+		totalPiratesReward: 3456789,
+	}
+
+	msg, err := n.render(killsTemplate, &journal.Bounty{TotalReward: 123456})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "12 kills in 1h35m, last bounty 123,456 CR"; msg != want {
+		t.Fatalf("wantMsg: %s, got: %s", want, msg)
+	}
+
+	msg, err = n.render(shieldsDownTemplate, &journal.ShieldState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Shields are down!"; msg != want {
+		t.Fatalf("wantMsg: %s, got: %s", want, msg)
+	}
+}
+
+func TestNotifier_renderLocalized(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    string
+		tmpl    string
+		j       journal.Event
+		wantMsg string
+	}{
+		{
+			name:    "italian hull damage",
+			lang:    "it",
+			tmpl:    hullDamageTemplate,
+			j:       &journal.HullDamage{Fighter: true, Health: 0.413871},
+			wantMsg: "Danni allo scafo del caccia, integrità al 41%",
+		},
+		{
+			name:    "german kills",
+			lang:    "de-AT",
+			tmpl:    killsTemplate,
+			j:       &journal.Bounty{},
+			wantMsg: "Gesamtbelohnung: 3.456.789 Credits\nZerstörte Piraten: 12",
+		},
+		{
+			name:    "french hull damage",
+			lang:    "fr",
+			tmpl:    hullDamageTemplate,
+			j:       &journal.HullDamage{Health: 0.399871},
+			wantMsg: "Dégâts à la coque du vaisseau, intégrité à 40\u202f%",
+		},
+		{
+			name:    "german threat",
+			lang:    "de",
+			tmpl:    threatTemplate,
+			j:       &journal.ShipTargeted{Ship: "anaconda", PilotRank: "Deadly", ShieldHealth: 64.5, HullHealth: 100},
+			wantMsg: "Gefährlicher Kontakt: anaconda (Tödlich), Schilde 65\u00a0%, Hülle 100\u00a0%",
+		},
+		{
+			name:    "italian interdiction",
+			lang:    "it",
+			tmpl:    interdictedTemplate,
+			j:       &journal.Interdicted{Interdictor: "Lobo", CombatRank: 7},
+			wantMsg: "Interdetto da Lobo (Letale), non sei riuscito a sfuggire",
+		},
+		{
+			name:    "spanish crime",
+			lang:    "es",
+			tmpl:    commitCrimeTemplate,
+			j:       &journal.CommitCrime{CrimeType: "fireInNoFireZone", Faction: "Ngalinn Crimson Boys", Fine: 400},
+			wantMsg: "Delito cometido: disparo en zona de alto el fuego, multa de 400 créditos de Ngalinn Crimson Boys",
+		},
+		{
+			name:    "crime without translation",
+			lang:    "fr",
+			tmpl:    commitCrimeTemplate,
+			j:       &journal.CommitCrime{CrimeType: "dockingMinorTresspass"},
+			wantMsg: "Crime commis : docking minor tresspass",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, err := parseLanguage(tt.lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			templates, err := parseTemplates(nil, lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n := &Notifier{
+				cfg:                &Cfg{},
+				templates:          templates,
+				killedPirates:      12,
+				totalPiratesReward: 3456789,
+			}
+
+			msg, err := n.render(tt.tmpl, tt.j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != tt.wantMsg {
+				t.Fatalf("wantMsg: %q, got: %q", tt.wantMsg, msg)
+			}
+		})
+	}
+
+	if _, err := parseLanguage("ja"); err == nil {
+		t.Fatalf("expected error for unsupported language")
+	}
+}
@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+// ThreatCfg defines the contacts worth a notification as soon as they are scanned, before
+// they break the shields
+type ThreatCfg struct {
+	MinRank     string   // pilot rank from which a contact is dangerous, e.g. "Deadly" (default: none)
+	Ships       []string // ship types always dangerous, e.g. "anaconda" or "Federal Corvette"
+	Players     bool     // every player ship is dangerous
+	UnderAttack bool     // notify when the ship, the fighter or the mothership is under attack
+	Scans       bool     // notify when the ship is scanned
+}
+
+type threat struct {
+	minRank     int // index in combatRanks, -1 when ranks are ignored
+	ships       map[string]bool
+	players     bool
+	underAttack bool
+	scans       bool
+}
+
+func newThreat(cfg ThreatCfg) (*threat, error) {
+	t := &threat{
+		minRank:     -1,
+		ships:       make(map[string]bool),
+		players:     cfg.Players,
+		underAttack: cfg.UnderAttack,
+		scans:       cfg.Scans,
+	}
+
+	if cfg.MinRank != "" {
+		t.minRank = rankIndex(cfg.MinRank)
+		if t.minRank < 0 {
+			return nil, fmt.Errorf("unknown threat rank %q, valid ranks are: %s", cfg.MinRank, strings.Join(combatRanks, ", "))
+		}
+	}
+
+	for _, s := range cfg.Ships {
+		t.ships[strings.ToLower(s)] = true
+	}
+
+	return t, nil
+}
+
+// rankIndex returns the index of the rank in combatRanks, or -1
+func rankIndex(rank string) int {
+	for i, r := range combatRanks {
+		if strings.EqualFold(r, rank) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isPlayer returns whether the pilot name of a ShipTargeted event is of a commander
+func isPlayer(pilotName string) bool {
+	return strings.HasPrefix(pilotName, "$cmdr_decorate")
+}
+
+// dangerous returns whether the targeted ship is a dangerous contact. The rank is known only
+// from the first scan stage.
+func (t *threat) dangerous(j *journal.ShipTargeted) bool {
+	if !j.TargetLocked {
+		return false
+	}
+
+	if t.ships[strings.ToLower(j.Ship)] || (j.ShipLocalised != "" && t.ships[strings.ToLower(j.ShipLocalised)]) {
+		return true
+	}
+	if t.players && isPlayer(j.PilotName) {
+		return true
+	}
+	if rank := rankIndex(j.PilotRank); t.minRank >= 0 && rank >= t.minRank {
+		return true
+	}
+
+	return false
+}
+
+func shipTargetedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	j := ev.(*journal.ShipTargeted)
+	if e.threat == nil || !e.threat.dangerous(j) {
+		return nil
+	}
+
+	// Each contact is reported once, from the scan stage revealing the pilot: at stage 0 all
+	// the contacts with the same ship look alike
+	if j.PilotName == "" {
+		return nil
+	}
+	contact := j.PilotName + "/" + j.Ship
+	if e.contacts[contact] {
+		return nil
+	}
+	e.contacts[contact] = true
+
+	category := categoryThreat
+	if isPlayer(j.PilotName) {
+		category = categoryCritical
+	}
+
+	return e.notifyTemplate(threatTemplate, category, j, skipNotify)
+}
+
+func underAttackEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if e.threat == nil || !e.threat.underAttack {
+		return nil
+	}
+
+	return e.notifyTemplate(underAttackTemplate, categoryThreat, ev, skipNotify)
+}
+
+func scannedEvent(e *Notifier, ev journal.Event, skipNotify bool) error {
+	if e.threat == nil || !e.threat.scans {
+		return nil
+	}
+
+	return e.notifyTemplate(scannedTemplate, categoryThreat, ev, skipNotify)
+}
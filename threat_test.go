@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_newThreat(t *testing.T) {
+	if _, err := newThreat(ThreatCfg{MinRank: "deadly"}); err != nil {
+		t.Fatalf("want: nil, got: %v", err)
+	}
+	if _, err := newThreat(ThreatCfg{MinRank: "Admiral"}); err == nil {
+		t.Fatalf("expected error with an unknown rank")
+	}
+}
+
+func Test_shipTargetedEvent(t *testing.T) {
+	threats, err := newThreat(ThreatCfg{MinRank: "Deadly", Ships: []string{"Federal Corvette"}, Players: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		j       *journal.ShipTargeted
+		wantMsg string
+	}{
+		{
+			name: "not locked",
+			j:    &journal.ShipTargeted{Ship: "anaconda", PilotRank: "Elite"},
+		},
+		{
+			name: "low rank",
+			j:    &journal.ShipTargeted{TargetLocked: true, Ship: "anaconda", PilotName: "$npc_name_decorate:#name=Lee Kim;", PilotRank: "Master"},
+		},
+		{
+			name: "high rank",
+			j: &journal.ShipTargeted{
+				TargetLocked:       true,
+				Ship:               "anaconda",
+				ScanStage:          3,
+				PilotName:          "$npc_name_decorate:#name=Kara Voss;",
+				PilotNameLocalised: "Kara Voss",
+				PilotRank:          "Elite",
+				LegalStatus:        "Wanted",
+				ShieldHealth:       100,
+				HullHealth:         87.4,
+			},
+			wantMsg: "Dangerous contact: anaconda piloted by Kara Voss (Elite), Wanted, shields 100%, hull 87%",
+		},
+		{
+			name: "same contact",
+			j:    &journal.ShipTargeted{TargetLocked: true, Ship: "anaconda", PilotName: "$npc_name_decorate:#name=Kara Voss;", PilotRank: "Elite"},
+		},
+		{
+			name: "pilot not scanned yet",
+			j:    &journal.ShipTargeted{TargetLocked: true, Ship: "federation_corvette", ShipLocalised: "Federal Corvette"},
+		},
+		{
+			name:    "ship type",
+			j:       &journal.ShipTargeted{TargetLocked: true, Ship: "federation_corvette", ShipLocalised: "Federal Corvette", ScanStage: 1, PilotName: "$npc_name_decorate:#name=Ada Roe;", PilotNameLocalised: "Ada Roe"},
+			wantMsg: "Dangerous contact: Federal Corvette piloted by Ada Roe",
+		},
+		{
+			name: "same ship at stage 0",
+			j:    &journal.ShipTargeted{TargetLocked: true, Ship: "federation_corvette", ShipLocalised: "Federal Corvette"},
+		},
+		{
+			name:    "another pilot with the same ship",
+			j:       &journal.ShipTargeted{TargetLocked: true, Ship: "federation_corvette", ShipLocalised: "Federal Corvette", ScanStage: 1, PilotName: "$npc_name_decorate:#name=Ivo Lind;", PilotNameLocalised: "Ivo Lind"},
+			wantMsg: "Dangerous contact: Federal Corvette piloted by Ivo Lind",
+		},
+		{
+			name:    "player",
+			j:       &journal.ShipTargeted{TargetLocked: true, Ship: "python", PilotName: "$cmdr_decorate:#name=Lobo;", PilotNameLocalised: "CMDR Lobo", PilotRank: "Novice"},
+			wantMsg: "Dangerous contact: python piloted by CMDR Lobo (Novice)",
+		},
+	}
+
+	n := &Notifier{cfg: &Cfg{}, threat: threats, contacts: make(map[string]bool)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &mockBot{}
+			n.bot = bot
+
+			if err := shipTargetedEvent(n, tt.j, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
+
+func Test_underAttackEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ThreatCfg
+		target  string
+		wantMsg string
+	}{
+		{name: "disabled", target: "You"},
+		{name: "ship", cfg: ThreatCfg{UnderAttack: true}, target: "You", wantMsg: "You are under attack"},
+		{name: "fighter", cfg: ThreatCfg{UnderAttack: true}, target: "Fighter", wantMsg: "Your fighter is under attack"},
+		{name: "mothership", cfg: ThreatCfg{UnderAttack: true}, target: "Mothership", wantMsg: "Your ship is under attack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threats, err := newThreat(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bot := &mockBot{}
+			n := &Notifier{cfg: &Cfg{}, bot: bot, threat: threats}
+
+			if err := underAttackEvent(n, &journal.UnderAttack{Target: tt.target}, false); err != nil {
+				t.Fatal(err)
+			}
+			if bot.sentMsg != tt.wantMsg {
+				t.Fatalf("wantMsg: %s, got: %s", tt.wantMsg, bot.sentMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleCfg configures the suppression of repeated notifications
+type ThrottleCfg struct {
+	DedupWindow     time.Duration            // notifications identical to the previous one of the event within this window are suppressed
+	Cooldowns       map[string]time.Duration // per journal event (e.g. "ShieldState"), minimum time between notifications
+	IncludeCritical bool                     // also throttle critical notifications, exempt by default
+}
+
+// throttle suppresses notifications sent too often, counting them so that the next
+// delivered notification of the same event can report the repetitions
+type throttle struct {
+	dedupWindow     time.Duration
+	cooldowns       map[string]time.Duration // keyed by lowercase event name
+	includeCritical bool
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time // by source and event
+	lastText map[string]string    // last delivered text, by source and event
+	repeated map[string]int       // suppressed notifications by source and event
+}
+
+// stateEvents report a change of state, so that the same text twice in a row is a real
+// change back and forth rather than a duplicate, e.g. shields down, up and down again.
+// Only their cooldowns apply.
+var stateEvents = map[string]bool{
+	"shieldstate": true,
+}
+
+// defaultCooldowns apply to the events without a configured cooldown, so that flapping
+// shields send one notification reporting the repetitions rather than a burst. A configured
+// cooldown of 0 disables them.
+var defaultCooldowns = map[string]time.Duration{
+	"shieldstate": 30 * time.Second,
+}
+
+func newThrottle(cfg ThrottleCfg) (*throttle, error) {
+	t := &throttle{
+		dedupWindow:     cfg.DedupWindow,
+		cooldowns:       make(map[string]time.Duration),
+		includeCritical: cfg.IncludeCritical,
+		lastSent:        make(map[string]time.Time),
+		lastText:        make(map[string]string),
+		repeated:        make(map[string]int),
+	}
+
+	for event, d := range defaultCooldowns {
+		t.cooldowns[event] = d
+	}
+	for event, d := range cfg.Cooldowns {
+		if d < 0 {
+			return nil, fmt.Errorf("invalid negative cooldown for %s", event)
+		}
+		t.cooldowns[strings.ToLower(event)] = d
+	}
+
+	return t, nil
+}
+
+// allow returns whether the notification must be delivered and, in that case, how many
+// notifications of the same event have been suppressed since the previous delivery
+func (t *throttle) allow(n notification, now time.Time) (bool, int) {
+	if n.Event == "" || (n.Category == categoryCritical && !t.includeCritical) {
+		return true, 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Notifications of different journal sources are throttled independently
+	event := strings.ToLower(n.Event)
+	eventKey := n.Source + "\x00" + event
+
+	// A notification is a duplicate of the last one delivered for the same event only
+	last, sent := t.lastSent[eventKey]
+	duplicate := sent && !stateEvents[event] && t.lastText[eventKey] == n.Text && now.Sub(last) < t.dedupWindow
+	cooldown, ok := t.cooldowns[event]
+	inCooldown := ok && now.Sub(last) < cooldown
+
+	if duplicate || inCooldown {
+		t.repeated[eventKey]++
+		return false, 0
+	}
+
+	t.lastText[eventKey] = n.Text
+	t.lastSent[eventKey] = now
+
+	repeated := t.repeated[eventKey]
+	delete(t.repeated, eventKey)
+
+	return true, repeated
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tommyblue/ED-AFK-Notifier/journal"
+)
+
+func Test_throttle(t *testing.T) {
+	thr, err := newThrottle(ThrottleCfg{
+		DedupWindow: time.Minute,
+		Cooldowns:   map[string]time.Duration{"hulldamage": 30 * time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	down := notification{Event: "ShieldState", Category: categoryShields, Text: "Shields are down!"}
+	up := notification{Event: "ShieldState", Category: categoryShields, Text: "Shields are up again"}
+	attack := notification{Event: "UnderAttack", Category: categoryThreat, Text: "You are under attack"}
+	fighter := notification{Event: "UnderAttack", Category: categoryThreat, Text: "Your fighter is under attack"}
+	hull := notification{Event: "HullDamage", Category: categoryHull, Text: "Ship hull damage detected, integrity is 80%"}
+	critical := notification{Event: "HullDamage", Category: categoryCritical, Text: "Ship hull damage detected, integrity is 20%"}
+
+	tests := []struct {
+		name         string
+		n            notification
+		at           time.Duration
+		wantAllowed  bool
+		wantRepeated int
+	}{
+		{name: "first shields down", n: down, at: 0, wantAllowed: true},
+		{name: "shields up in the default cooldown", n: up, at: 10 * time.Second, wantAllowed: false},
+		{name: "shields down in the default cooldown", n: down, at: 20 * time.Second, wantAllowed: false},
+		{name: "shields down after the default cooldown", n: down, at: 30 * time.Second, wantAllowed: true, wantRepeated: 2},
+		{name: "first under attack", n: attack, at: 0, wantAllowed: true},
+		{name: "duplicated under attack", n: attack, at: 10 * time.Second, wantAllowed: false},
+		{name: "fighter under attack", n: fighter, at: 20 * time.Second, wantAllowed: true, wantRepeated: 1},
+		{name: "ship under attack again", n: attack, at: 30 * time.Second, wantAllowed: true},
+		{name: "duplicated ship under attack", n: attack, at: 40 * time.Second, wantAllowed: false},
+		{name: "under attack after the window", n: attack, at: 90 * time.Second, wantAllowed: true, wantRepeated: 1},
+		{name: "first hull damage", n: hull, at: 0, wantAllowed: true},
+		{name: "hull damage in cooldown", n: notification{Event: "HullDamage", Text: "other"}, at: 10 * time.Second, wantAllowed: false},
+		{name: "critical is exempt", n: critical, at: 11 * time.Second, wantAllowed: true},
+		{name: "hull damage after cooldown", n: notification{Event: "HullDamage", Text: "other"}, at: 31 * time.Second, wantAllowed: true, wantRepeated: 1},
+		{name: "under attack of another journal", n: notification{Source: "other", Event: "UnderAttack", Text: "You are under attack"}, at: 91 * time.Second, wantAllowed: true},
+		{name: "hull damage of another journal", n: notification{Source: "other", Event: "HullDamage", Text: "other"}, at: 32 * time.Second, wantAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, repeated := thr.allow(tt.n, now.Add(tt.at))
+			if allowed != tt.wantAllowed || repeated != tt.wantRepeated {
+				t.Fatalf("want: %t, %d, got: %t, %d", tt.wantAllowed, tt.wantRepeated, allowed, repeated)
+			}
+		})
+	}
+}
+
+func TestNotifier_notifyThrottled(t *testing.T) {
+	thr, err := newThrottle(ThrottleCfg{DedupWindow: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	threats, err := newThreat(ThreatCfg{UnderAttack: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := &mockBot{}
+	n := &Notifier{cfg: &Cfg{}, bot: bot, throttle: thr, threat: threats}
+
+	for i := 0; i < 3; i++ {
+		if err := underAttackEvent(n, &journal.UnderAttack{Header: journal.Header{Event: journal.UnderAttackEvent}, Target: "You"}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := underAttackEvent(n, &journal.UnderAttack{Header: journal.Header{Event: journal.UnderAttackEvent}, Target: "Fighter"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Your fighter is under attack (repeated 2 times)"; bot.sentMsg != want {
+		t.Fatalf("wantMsg: %s, got: %s", want, bot.sentMsg)
+	}
+
+	// Without cooldown, shields going down, up and down again are all delivered
+	thr, err = newThrottle(ThrottleCfg{DedupWindow: time.Hour, Cooldowns: map[string]time.Duration{"ShieldState": 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.throttle = thr
+	for _, up := range []bool{false, true, false} {
+		bot.sentMsg = ""
+		if err := shieldStateEvent(n, &journal.ShieldState{Header: journal.Header{Event: journal.ShieldStateEvent}, ShieldsUp: up}, false); err != nil {
+			t.Fatal(err)
+		}
+		if bot.sentMsg == "" {
+			t.Fatalf("shields up %t suppressed", up)
+		}
+	}
+}